@@ -0,0 +1,380 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/skye-lopez/go-get-cli/installer"
+	"github.com/skye-lopez/go-get-cli/interaction"
+	"github.com/skye-lopez/go-get-cli/resolver"
+	"github.com/skye-lopez/go-get-cli/store"
+)
+
+// targetDir is the module installs from the menus go into, empty meaning the working directory.
+var targetDir string
+
+// promptCreator is anything prompts for an entry's options can be added to.
+type promptCreator interface {
+	CreatePrompt(title string, description string, isPaginated bool) *interaction.Prompt
+}
+
+// addInstallOptions fills an entry's prompt with what can be done with the package.
+// It is shared by every command that lets you browse down to a single entry.
+func addInstallOptions(prompts promptCreator, entryPrompt *interaction.Prompt, e store.Entry) {
+	installPath, _, err := resolver.Static(e.Link)
+	if err != nil {
+		entryPrompt.AddOption("Not installable", "The link does not point at a Go package ("+e.Link+")", e)
+		return
+	}
+
+	addGet := func() {
+		getOption := entryPrompt.AddOption("Install via go get", "go get "+installPath, e)
+		getOption.AddCallback(func(...any) (string, error) {
+			return installEntry(e, false)
+		})
+	}
+	addBinary := func() {
+		binOption := entryPrompt.AddOption("Install binary (go install pkg@latest)", "go install "+installPath+"@latest", e)
+		binOption.AddCallback(func(...any) (string, error) {
+			return installEntry(e, true)
+		})
+	}
+
+	// Offer whichever is more likely to be wanted first
+	if installer.LooksLikeCommand(installPath, e.Category) {
+		addBinary()
+		addGet()
+	} else {
+		addGet()
+		addBinary()
+	}
+
+	versionOption := entryPrompt.AddOption("Pick a version", "go get "+installPath+"@<version>", e)
+	versionPrompt := prompts.CreatePrompt(e.Name+" - Versions", "", true)
+	versionPrompt.List.Labels = map[interaction.Action]string{interaction.ActionSelect: "Install version"}
+	versionPrompt.AttachParent(entryPrompt.Idx)
+	versionOption.AttachPrompt(versionPrompt.Idx)
+
+	// Listing versions goes to the network, so only do it once someone asks. Until it works it is tried
+	// again every time the prompt opens, a network hiccup shouldn't leave it empty for good.
+	loaded := false
+	versionPrompt.OnOpen = func(p *interaction.Prompt) {
+		if loaded {
+			return
+		}
+		p.ClearOptions()
+		loaded = addVersionOptions(p, e)
+	}
+
+	// Removing opens a confirmation first, which says whether anything still imports the package
+	removeOption := entryPrompt.AddOption("Remove from module", "go get "+installPath+"@none && go mod tidy", e)
+	removePrompt := prompts.CreatePrompt(e.Name+" - Remove", "", true)
+	removePrompt.AttachParent(entryPrompt.Idx)
+	removeOption.AttachPrompt(removePrompt.Idx)
+	removePrompt.OnOpen = func(p *interaction.Prompt) {
+		p.ClearOptions()
+		addRemoveChoices(p, e)
+	}
+
+	addUndoOption(entryPrompt, e)
+	addTargetOption(prompts, entryPrompt, e)
+}
+
+// addRemoveChoices fills the remove confirmation, warning about any files that still import the package.
+func addRemoveChoices(p *interaction.Prompt, e store.Entry) {
+	path, err := modules.Resolve(e.Link)
+	if err != nil {
+		p.AddOption("Not removable", "This package can't be resolved to a module. "+err.Error(), e)
+		return
+	}
+
+	mod, importers, err := installer.Importers(targetDir, path)
+	if errors.Is(err, installer.ErrNotRequired) {
+		p.AddOption("Nothing to remove", "This package is not a dependency of the module.", e)
+		return
+	}
+	if err != nil {
+		p.AddOption("Could not read the module", err.Error(), e)
+		return
+	}
+
+	if len(importers) == 0 {
+		option := p.AddOption("Remove "+mod, "go get "+mod+"@none && go mod tidy", e)
+		option.AddCallback(func(...any) (string, error) {
+			return removeEntry(path, false)
+		})
+		return
+	}
+	option := p.AddOption("Remove "+mod+" anyway, the build will break",
+		"Still imported by "+strings.Join(importers, ", ")+". go mod tidy will be skipped since it would only add the module back.", e)
+	option.AddCallback(func(...any) (string, error) {
+		return removeEntry(path, true)
+	})
+}
+
+// removeEntry drops the module path belongs to from the target module.
+func removeEntry(path string, force bool) (string, error) {
+	result, err := goInstaller.Remove(menuContext, installer.Request{Path: path, Dir: targetDir, Force: force})
+	if errors.Is(err, installer.ErrNotRequired) {
+		return "This package is not a dependency of the module, nothing to remove.", nil
+	}
+	if err != nil {
+		return "Error removing the selected package.", err
+	}
+	lastInstall = result
+	return removedMessage(result), nil
+}
+
+// removedMessage is the result screen after a removal, warning about anything that still imports the module.
+func removedMessage(r *installer.Result) string {
+	message := "Removed " + r.Module + " from go.mod."
+	if len(r.StillImported) > 0 {
+		message = "Removed " + r.Module + " from go.mod, but these files still import it so the build will break:\n  " +
+			strings.Join(r.StillImported, "\n  ") +
+			"\n\ngo mod tidy was skipped since it would only add the module back."
+	}
+	return message
+}
+
+// addTargetOption shows which module an install would modify, and lets the user create one or pick
+// another out of the workspace. Both are looked up again every time the prompt opens since they can change.
+func addTargetOption(prompts promptCreator, entryPrompt *interaction.Prompt, e store.Entry) {
+	// Filled in by describe when the entry is opened, there is no point looking for go.mod for every entry up front
+	targetOption := entryPrompt.AddOption("Target module", "", e)
+	targetPrompt := prompts.CreatePrompt("Target module", "", true)
+	targetPrompt.AttachParent(entryPrompt.Idx)
+	targetOption.AttachPrompt(targetPrompt.Idx)
+
+	describe := func() {
+		t, err := installer.FindModule(targetDir)
+		if err != nil {
+			targetOption.Title = "Target module: none found"
+			targetOption.Description = "go get needs a go.mod, select to create one or pick a workspace module"
+			return
+		}
+		targetOption.Title = "Target module: " + t.Path
+		targetOption.Description = "go.mod in " + t.Dir
+	}
+	previous := entryPrompt.OnOpen
+	entryPrompt.OnOpen = func(p *interaction.Prompt) {
+		if previous != nil {
+			previous(p)
+		}
+		describe()
+	}
+
+	targetPrompt.OnOpen = func(p *interaction.Prompt) {
+		p.ClearOptions()
+		addTargetChoices(p, e)
+	}
+}
+
+func addTargetChoices(p *interaction.Prompt, e store.Entry) {
+	current, err := installer.FindModule(targetDir)
+
+	if err != nil {
+		dir, _ := filepath.Abs(targetDir)
+		name := filepath.Base(dir)
+		initOption := p.AddOption("Initialize a module here (go mod init "+name+")", "Creates "+filepath.Join(dir, "go.mod"), e)
+		initOption.AddCallback(func(...any) (string, error) {
+			if _, err := goInstaller.Init(menuContext, dir, name); err != nil {
+				return "Error creating the module.", err
+			}
+			return "Created module " + name + ", packages will be installed into it.", nil
+		})
+	}
+
+	if current.GoWork != "" {
+		workspace, err := installer.WorkspaceModules(current.GoWork)
+		if err != nil {
+			p.AddOption("Could not read "+current.GoWork, err.Error(), e)
+		}
+		for _, t := range workspace {
+			if t.Dir == current.Dir {
+				continue
+			}
+			dir := t.Dir
+			option := p.AddOption("Use workspace module "+t.Path, "go.mod in "+t.Dir, e)
+			option.AddCallback(func(...any) (string, error) {
+				targetDir = dir
+				return "Packages will be installed into " + dir + ".", nil
+			})
+		}
+	}
+
+	if targetDir != "" {
+		option := p.AddOption("Use the working directory", "Go back to installing wherever go-get-cli was run from", e)
+		option.AddCallback(func(...any) (string, error) {
+			targetDir = ""
+			return "Packages will be installed into the working directory.", nil
+		})
+	}
+
+	if p.Len() == 0 {
+		p.AddOption("Nothing to change", "Installing into "+current.Path+" ("+current.Dir+")", e)
+	}
+}
+
+// addVersionOptions lists every published version of an entry, newest first, each one installing that version.
+// When they can't be listed the reason is shown instead and false is returned.
+func addVersionOptions(p *interaction.Prompt, e store.Entry) bool {
+	path, err := modules.Resolve(e.Link)
+	if err != nil {
+		p.AddOption("Not installable", err.Error(), e)
+		return false
+	}
+
+	mod, available, err := versions.Versions(path)
+	if errors.Is(err, installer.ErrNoProxy) {
+		p.AddOption("No proxy to list versions from", "go get can still fetch the latest version directly. "+err.Error(), e)
+		return false
+	}
+	if err != nil {
+		p.AddOption("No versions found", err.Error(), e)
+		return false
+	}
+
+	for _, v := range available {
+		title := v.Version
+		if v.Prerelease {
+			title += " (pre-release)"
+		}
+		if v.Retracted {
+			title += " (retracted)"
+		}
+		description := "go get " + path + "@" + v.Version
+		if v.Rationale != "" {
+			description += " - retracted: " + v.Rationale
+		}
+
+		version := v.Version
+		option := p.AddOption(title, description, e)
+		option.AddCallback(func(...any) (string, error) {
+			result, err := goInstaller.Get(menuContext, installer.Request{Path: path, Version: version, Dir: targetDir})
+			if err != nil {
+				return "Error installing " + mod + "@" + version + ".", err
+			}
+			lastInstall = result
+			return installedMessage(result), nil
+		})
+	}
+	return true
+}
+
+// installEntry works out the import path of an entry and either go gets it into the module in the
+// working directory, or go installs it as a binary.
+func installEntry(e store.Entry, binary bool) (string, error) {
+	path, err := modules.Resolve(e.Link)
+	if err != nil {
+		return "This package can't be installed with go.", err
+	}
+
+	req := installer.Request{Path: path, Dir: targetDir}
+	if binary {
+		result, err := goInstaller.Install(menuContext, req)
+		if errors.Is(err, installer.ErrNotMain) {
+			return "This package is a library, not a command. Try installing it via go get instead.", err
+		}
+		if err != nil {
+			return "Error installing the selected binary.", err
+		}
+		return binaryMessage(result), nil
+	}
+
+	result, err := goInstaller.Get(menuContext, req)
+	if err != nil {
+		return "Error installing the selected package.", err
+	}
+	lastInstall = result
+	return installedMessage(result), nil
+}
+
+func binaryMessage(r *installer.Result) string {
+	return fmt.Sprintf("Installed %s@%s to your $GOBIN! Have fun :)", r.Module, r.Version)
+}
+
+// installedMessage is the result screen after a go get, spelling out what happened to go.mod.
+func installedMessage(r *installer.Result) string {
+	message := "Package installed! Have fun :)"
+	if r.Module != "" {
+		message = fmt.Sprintf("Installed %s %s! Have fun :)", r.Module, r.Version)
+	}
+	if len(r.Requires) == 0 {
+		return message + "\n\ngo.mod already had everything it needed."
+	}
+
+	lines := []string{}
+	for _, c := range r.Requires {
+		indirect := ""
+		if c.Indirect {
+			indirect = " // indirect"
+		}
+		switch {
+		case c.Added():
+			lines = append(lines, fmt.Sprintf("+ require %s %s%s", c.Path, c.New, indirect))
+		case c.Removed():
+			lines = append(lines, fmt.Sprintf("- require %s %s%s", c.Path, c.Old, indirect))
+		default:
+			lines = append(lines, fmt.Sprintf("~ require %s %s => %s%s", c.Path, c.Old, c.New, indirect))
+		}
+	}
+	return message + "\n\ngo.mod changes:\n  " + strings.Join(lines, "\n  ") + "\n\nUse \"Undo last change\" to put go.mod and go.sum back."
+}
+
+// lastInstall is the most recent install or removal from the menus, kept so it can be undone.
+var lastInstall *installer.Result
+
+func addUndoOption(entryPrompt *interaction.Prompt, e store.Entry) {
+	undoOption := entryPrompt.AddOption("Undo last change", "Restore go.mod and go.sum to how they were before the last install or removal", e)
+	undoOption.AddCallback(func(...any) (string, error) {
+		if lastInstall == nil {
+			return "Nothing to undo.", nil
+		}
+		if err := lastInstall.Undo(); err != nil {
+			return "Error restoring go.mod and go.sum.", err
+		}
+		module := lastInstall.Module
+		lastInstall = nil
+		return "Undid the last change to " + module + ", go.mod and go.sum are back the way they were.", nil
+	})
+}
+
+// batchInstall is the [i] action in the menus, it go gets every selected package one after the other.
+// The undo snapshot is taken from the first install, so "Undo last change" rolls back the whole batch.
+// Progress goes to the menu's status bar through progress.
+func batchInstall(ctx context.Context, progress func(message string), selected []*interaction.Option) (string, error) {
+	lastInstall = nil
+	installed, failed := []string{}, []string{}
+
+	for n, o := range selected {
+		e, ok := o.Packet.(store.Entry)
+		if !ok {
+			continue
+		}
+		progress(fmt.Sprintf("[%d/%d] installing %s...", n+1, len(selected), e.Name))
+
+		path, err := modules.Resolve(e.Link)
+		if err != nil {
+			failed = append(failed, e.Name+": "+err.Error())
+			continue
+		}
+		result, err := goInstaller.Get(ctx, installer.Request{Path: path, Dir: targetDir})
+		if lastInstall == nil && result != nil {
+			lastInstall = result
+		}
+		if err != nil {
+			failed = append(failed, e.Name+": "+err.Error())
+			continue
+		}
+		installed = append(installed, e.Name)
+	}
+
+	message := fmt.Sprintf("Installed %d of %d packages.", len(installed), len(selected))
+	if len(failed) > 0 {
+		return message, errors.New("failed to install:\n  " + strings.Join(failed, "\n  "))
+	}
+	return message + " Have fun :)", nil
+}
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/skye-lopez/go-get-cli/installer"
+	"github.com/skye-lopez/go-get-cli/interaction"
+	"github.com/skye-lopez/go-get-cli/resolver"
+	"github.com/skye-lopez/go-get-cli/store"
+)
+
+func TestVersionsRetriedUntilListed(t *testing.T) {
+	// An empty file proxy has no versions of anything until the list is written
+	proxy := t.TempDir()
+	previousVersions, previousModules := versions, modules
+	versions, modules = &installer.ProxyClient{Proxy: "file://" + filepath.ToSlash(proxy)}, &resolver.Resolver{}
+	defer func() { versions, modules = previousVersions, previousModules }()
+
+	i := interaction.NewInteraction()
+	home := i.CreatePrompt("Home", "", false)
+	entryPrompt := i.CreatePrompt("Entry", "", false)
+	entryPrompt.AttachParent(home.Idx)
+	addInstallOptions(i, entryPrompt, store.Entry{Name: "cobra", Link: "https://github.com/spf13/cobra"})
+
+	var versionPrompt *interaction.Prompt
+	for _, o := range entryPrompt.List.Options {
+		if o.Title == "Pick a version" {
+			versionPrompt = i.Prompts[o.PromptIdx]
+		}
+	}
+	if versionPrompt == nil {
+		t.Fatal("no Pick a version option")
+	}
+	titles := func() []string {
+		titles := []string{}
+		for _, o := range versionPrompt.List.Options {
+			titles = append(titles, o.Title)
+		}
+		return titles
+	}
+
+	i.Navigate(versionPrompt.Idx)
+	if got := titles(); len(got) != 1 || got[0] != "No versions found" {
+		t.Fatalf("options %q, want No versions found", got)
+	}
+
+	dir := filepath.Join(proxy, "github.com", "spf13", "cobra", "@v")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "list"), []byte("v1.8.0\nv1.8.1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	i.Navigate(entryPrompt.Idx)
+	i.Navigate(versionPrompt.Idx)
+	if got := titles(); len(got) != 2 || got[0] != "v1.8.1" || got[1] != "v1.8.0" {
+		t.Fatalf("options %q after the proxy came back, want v1.8.1 and v1.8.0", got)
+	}
+
+	// Once listed they are kept, even if the proxy goes away again
+	os.RemoveAll(proxy)
+	i.Navigate(entryPrompt.Idx)
+	i.Navigate(versionPrompt.Idx)
+	if got := titles(); len(got) != 2 {
+		t.Errorf("options %q, want the versions listed before", got)
+	}
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"errors"
+	"io/fs"
+	"syscall"
+
+	"github.com/skye-lopez/go-get-cli/installer"
+	"github.com/skye-lopez/go-get-cli/interaction"
+	"github.com/skye-lopez/go-get-cli/store"
+)
+
+// Exit codes, so scripts can tell a missing network apart from everything else.
+const (
+	exitError   = 1
+	exitFetch   = 3
+	exitParse   = 4
+	exitCorrupt = 5
+
+	exitInterrupted = 130 // What shells use for a process ended by Ctrl-C, 128 + SIGINT
+)
+
+// explain turns an error from a command into something a person can act on, along with the exit code to use.
+func explain(err error) (string, int) {
+	switch {
+	case errors.Is(err, store.ErrFetch):
+		var pathErr *fs.PathError
+		if errors.As(err, &pathErr) {
+			return "Could not read the package catalog from " + pathErr.Path + ". Check the path given to --source.\n" +
+				"  " + err.Error(), exitFetch
+		}
+		return "Could not download the package catalog. Are you offline?\n" +
+			"The catalog only has to be downloaded once, after that it is cached. You can also point --source at a local file.\n" +
+			"  " + err.Error(), exitFetch
+	case errors.Is(err, store.ErrParse):
+		return "The package catalog was downloaded but no packages could be read out of it. Check --source.\n" +
+			"  " + err.Error(), exitParse
+	case errors.Is(err, installer.ErrNoModule):
+		return "There is no Go module here to add packages to. Run `go mod init` first, or point --dir at a module.\n" +
+			"  " + err.Error(), exitError
+	case errors.Is(err, installer.ErrNoGo):
+		return "The go command could not be found. Install Go from https://go.dev/dl/ and make sure it is on your $PATH.", exitError
+	case errors.Is(err, store.ErrCorruptStore):
+		return "The cached package catalog could not be read. Run `go-get-cli refresh` to download it again.\n" +
+			"  " + err.Error(), exitCorrupt
+	case errors.Is(err, interaction.ErrInterrupted):
+		// 128 + the signal number, like a shell reports for a process the signal killed
+		var interrupted *interaction.InterruptedError
+		if errors.As(err, &interrupted) {
+			if sig, ok := interrupted.Signal.(syscall.Signal); ok {
+				return "Interrupted.", 128 + int(sig)
+			}
+		}
+		return "Interrupted.", exitInterrupted
+	}
+	return err.Error(), exitError
+}
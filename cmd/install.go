@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/skye-lopez/go-get-cli/installer"
+	"github.com/skye-lopez/go-get-cli/resolver"
+	"github.com/spf13/cobra"
+)
+
+var installCommand = &cobra.Command{
+	Use:   "install <name-or-path>...",
+	Short: "Install packages without opening the menus",
+	Long: `Install packages by their name in the catalog, link or import path.
+    ~~~~~~~Add a package to the current module~~~~~~~
+    go-get-cli install cobra
+    go-get-cli install cobra@v1.8.0
+    go-get-cli install github.com/spf13/cobra
+    ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
+
+    ~~~~~~~Install a command line tool binary~~~~~~~~~
+    go-get-cli install golangci-lint --bin
+    ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
+
+    ~~~~~~Bootstrap a project with a few packages~~~~~~
+    go-get-cli install cobra zerolog pgx --dir ./service
+    go-get-cli install cobra zerolog pgx --dry-run
+    ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
+    `,
+
+	Args:        cobra.MinimumNArgs(1),
+	Annotations: map[string]string{catalogAnnotation: catalogLoad},
+	RunE:        install,
+}
+
+func init() {
+	rootCmd.AddCommand(installCommand)
+	installCommand.Flags().Bool("bin", false, "Install the package as a binary with go install pkg@latest instead of go get")
+	installCommand.Flags().Bool("dry-run", false, "Only print what would be installed")
+	installCommand.Flags().String("dir", "", "Directory of the module to install into (default is the working directory)")
+}
+
+// installRow is a line of the summary printed once every install has run.
+type installRow struct {
+	arg     string
+	path    string
+	version string
+	status  string
+}
+
+func install(cmd *cobra.Command, args []string) error {
+	binary, _ := cmd.Flags().GetBool("bin")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	dir, _ := cmd.Flags().GetString("dir")
+
+	// No point trying every package if there is nothing to add them to
+	if !binary {
+		if _, err := installer.FindModule(dir); err != nil {
+			return err
+		}
+	}
+
+	rows := []installRow{}
+	failed := 0
+
+	for n, arg := range args {
+		name, version, _ := strings.Cut(arg, "@")
+		row := installRow{arg: arg, version: version}
+
+		path, err := lookupPath(name, dryRun)
+		if err != nil {
+			row.status = "not found"
+			fmt.Fprintln(cmd.ErrOrStderr(), err)
+			rows = append(rows, row)
+			failed += 1
+			continue
+		}
+		row.path = path
+
+		req := installer.Request{Path: path, Version: version, Dir: dir}
+		if dryRun {
+			row.status = "dry run"
+			rows = append(rows, row)
+			continue
+		}
+
+		fmt.Fprintf(cmd.ErrOrStderr(), "[%d/%d] installing %s...\n", n+1, len(args), path)
+		var result *installer.Result
+		if binary {
+			result, err = goInstaller.Install(cmd.Context(), req)
+		} else {
+			result, err = goInstaller.Get(cmd.Context(), req)
+		}
+		if err != nil {
+			row.status = "failed"
+			fmt.Fprintln(cmd.ErrOrStderr(), err)
+			rows = append(rows, row)
+			failed += 1
+			continue
+		}
+
+		row.status = "installed"
+		if binary {
+			// The command may have been found under cmd/ in the package asked for
+			row.path = result.Module
+		}
+		if result.Version != "" {
+			row.version = result.Version
+		}
+		if result.Module == "" && !binary {
+			row.status = "already required"
+		}
+		rows = append(rows, row)
+	}
+
+	printInstallSummary(cmd.OutOrStdout(), rows, binary, dryRun)
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d packages could not be installed", failed, len(args))
+	}
+	return nil
+}
+
+func printInstallSummary(w io.Writer, rows []installRow, binary bool, dryRun bool) {
+	command := "go get"
+	if binary {
+		command = "go install"
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "PACKAGE\tIMPORT PATH\tVERSION\tSTATUS")
+	for _, r := range rows {
+		version := r.version
+		if version == "" {
+			version = "latest"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", r.arg, r.path, version, r.status)
+	}
+	tw.Flush()
+
+	if dryRun {
+		fmt.Fprintf(w, "\nNothing was installed, run again without --dry-run to %s these.\n", command)
+	}
+}
+
+// lookupPath turns what was typed on the command line into an import path.
+// Catalog names win, then links are resolved, and anything else is taken as an import path already.
+// Offline links are only resolved by the known host rules, for a dry run that shouldn't touch the network.
+func lookupPath(arg string, offline bool) (string, error) {
+	resolve := modules.Resolve
+	if offline {
+		resolve = func(link string) (string, error) {
+			path, _, err := resolver.Static(link)
+			return path, err
+		}
+	}
+
+	for _, v := range data.Entries {
+		if strings.EqualFold(v.Name, arg) {
+			return resolve(v.Link)
+		}
+	}
+
+	if strings.HasPrefix(arg, "http://") || strings.HasPrefix(arg, "https://") {
+		return resolve(arg)
+	}
+	if strings.Contains(arg, ".") {
+		return arg, nil
+	}
+
+	return "", errors.New("no package called " + arg + " in the catalog, try go-get-cli search " + arg)
+}
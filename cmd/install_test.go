@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/skye-lopez/go-get-cli/installer"
+	"github.com/skye-lopez/go-get-cli/resolver"
+)
+
+// fakeInstaller records what would have been installed instead of running the go command.
+type fakeInstaller struct {
+	requests []installer.Request
+	fail     map[string]bool // Import paths that fail to install
+}
+
+func (f *fakeInstaller) Get(ctx context.Context, req installer.Request) (*installer.Result, error) {
+	f.requests = append(f.requests, req)
+	if f.fail[req.Path] {
+		return &installer.Result{}, errors.New("go get " + req.Path + ": exit status 1")
+	}
+	version := req.Version
+	if version == "" {
+		version = "v1.0.0"
+	}
+	return &installer.Result{Module: req.Path, Version: version}, nil
+}
+
+func (f *fakeInstaller) Install(ctx context.Context, req installer.Request) (*installer.Result, error) {
+	return f.Get(ctx, req)
+}
+
+func (f *fakeInstaller) Init(ctx context.Context, dir string, path string) (*installer.Result, error) {
+	return nil, errors.New("not expected")
+}
+
+func (f *fakeInstaller) Remove(ctx context.Context, req installer.Request) (*installer.Result, error) {
+	return nil, errors.New("not expected")
+}
+
+// noMajors fails the test if the proxy would have been asked about a major version.
+type noMajors struct{ t *testing.T }
+
+func (m noMajors) NewestMajor(path string) (string, error) {
+	m.t.Errorf("asked the proxy about %s", path)
+	return path, nil
+}
+
+// withInstaller swaps in a fake installer, and a resolver that stays off the network, for the length of the test.
+func withInstaller(t *testing.T, fake *fakeInstaller) {
+	t.Helper()
+	previousInstaller, previousModules := goInstaller, modules
+	goInstaller, modules = fake, &resolver.Resolver{Majors: noMajors{t}}
+	t.Cleanup(func() {
+		goInstaller, modules = previousInstaller, previousModules
+		for name, value := range map[string]string{"bin": "false", "dry-run": "false", "dir": ""} {
+			installCommand.Flags().Set(name, value)
+		}
+	})
+}
+
+// summary is the install summary the last run printed, one row of fields per package.
+func summary() [][]string {
+	rows := [][]string{}
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	for _, line := range lines[1:] {
+		if line == "" {
+			break
+		}
+		rows = append(rows, strings.Fields(line))
+	}
+	return rows
+}
+
+func TestInstallSummary(t *testing.T) {
+	fake := &fakeInstaller{}
+	withInstaller(t, fake)
+	modules.Majors = nil
+	dir := tempModule(t)
+	path := filepath.Join(t.TempDir(), "store.json")
+
+	if err := run(t, "install", "cobra", "pgx@v5.7.1", "github.com/rs/zerolog", "--dir", dir, "--store", path, "--source", fixture); err != nil {
+		t.Fatal(err)
+	}
+	want := []installer.Request{
+		{Path: "github.com/spf13/cobra", Dir: dir},
+		{Path: "github.com/jackc/pgx", Version: "v5.7.1", Dir: dir},
+		{Path: "github.com/rs/zerolog", Dir: dir},
+	}
+	if !reflect.DeepEqual(fake.requests, want) {
+		t.Errorf("installed %+v, want %+v", fake.requests, want)
+	}
+	wantRows := [][]string{
+		{"cobra", "github.com/spf13/cobra", "v1.0.0", "installed"},
+		{"pgx@v5.7.1", "github.com/jackc/pgx", "v5.7.1", "installed"},
+		{"github.com/rs/zerolog", "github.com/rs/zerolog", "v1.0.0", "installed"},
+	}
+	if got := summary(); !reflect.DeepEqual(got, wantRows) {
+		t.Errorf("summary rows %q, want %q", got, wantRows)
+	}
+}
+
+func TestInstallDryRun(t *testing.T) {
+	fake := &fakeInstaller{}
+	withInstaller(t, fake)
+	dir := tempModule(t)
+	path := filepath.Join(t.TempDir(), "store.json")
+
+	if err := run(t, "install", "cobra", "pgx", "--dry-run", "--dir", dir, "--store", path, "--source", fixture); err != nil {
+		t.Fatal(err)
+	}
+	if len(fake.requests) != 0 {
+		t.Errorf("a dry run installed %+v", fake.requests)
+	}
+	wantRows := [][]string{
+		{"cobra", "github.com/spf13/cobra", "latest", "dry", "run"},
+		{"pgx", "github.com/jackc/pgx", "latest", "dry", "run"},
+	}
+	if got := summary(); !reflect.DeepEqual(got, wantRows) {
+		t.Errorf("summary rows %q, want %q", got, wantRows)
+	}
+	if !strings.Contains(stdout.String(), "Nothing was installed") {
+		t.Errorf("a dry run should say nothing was installed:\n%s", stdout.String())
+	}
+}
+
+func TestInstallDirWithoutModule(t *testing.T) {
+	fake := &fakeInstaller{}
+	withInstaller(t, fake)
+	path := filepath.Join(t.TempDir(), "store.json")
+
+	err := run(t, "install", "cobra", "--dir", t.TempDir(), "--store", path, "--source", fixture)
+	if !errors.Is(err, installer.ErrNoModule) {
+		t.Errorf("got %v, want ErrNoModule", err)
+	}
+	if len(fake.requests) != 0 {
+		t.Errorf("installed %+v without a module to install into", fake.requests)
+	}
+}
+
+func TestInstallPartialFailure(t *testing.T) {
+	fake := &fakeInstaller{fail: map[string]bool{"github.com/rs/zerolog": true}}
+	withInstaller(t, fake)
+	modules.Majors = nil
+	dir := tempModule(t)
+	path := filepath.Join(t.TempDir(), "store.json")
+
+	err := run(t, "install", "cobra", "nosuchpackage", "github.com/rs/zerolog", "--dir", dir, "--store", path, "--source", fixture)
+	if err == nil || err.Error() != "2 of 3 packages could not be installed" {
+		t.Errorf("got %v, want 2 of 3 failed", err)
+	}
+	if _, code := explain(err); code == 0 {
+		t.Errorf("a partial failure should exit non-zero")
+	}
+	wantRows := [][]string{
+		{"cobra", "github.com/spf13/cobra", "v1.0.0", "installed"},
+		{"nosuchpackage", "latest", "not", "found"},
+		{"github.com/rs/zerolog", "github.com/rs/zerolog", "latest", "failed"},
+	}
+	if got := summary(); !reflect.DeepEqual(got, wantRows) {
+		t.Errorf("summary rows %q, want %q", got, wantRows)
+	}
+	if !strings.Contains(stderr.String(), "no package called nosuchpackage") || !strings.Contains(stderr.String(), "exit status 1") {
+		t.Errorf("failures not reported on stderr:\n%s", stderr.String())
+	}
+}
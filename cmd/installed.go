@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/skye-lopez/go-get-cli/installer"
+	"github.com/skye-lopez/go-get-cli/resolver"
+	"github.com/skye-lopez/go-get-cli/store"
+	"github.com/spf13/cobra"
+)
+
+// onlyFlag filters list and search results down to installed or not installed packages.
+var onlyFlag string
+
+func addOnlyFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&onlyFlag, "only", "", "Only show packages that are \"installed\" in the current module, or \"not-installed\"")
+}
+
+func checkOnlyFlag() error {
+	switch onlyFlag {
+	case "", "installed", "not-installed":
+		return nil
+	}
+	return fmt.Errorf("unknown --only %q, expected installed or not-installed", onlyFlag)
+}
+
+// requiredModules reads go.mod of the target module. Outside a module nothing is installed.
+func requiredModules() installer.Requirements {
+	reqs, err := installer.Required(targetDir)
+	if err != nil {
+		return installer.Requirements{}
+	}
+	return reqs
+}
+
+// installedVersion reports the version of an entry the module depends on, going by its link.
+func installedVersion(reqs installer.Requirements, e store.Entry) (string, bool) {
+	path, _, err := resolver.Static(e.Link)
+	if err != nil {
+		return "", false
+	}
+	return reqs.Version(path)
+}
+
+// showEntry applies --only to an entry.
+func showEntry(reqs installer.Requirements, e store.Entry) bool {
+	_, installed := installedVersion(reqs, e)
+	switch onlyFlag {
+	case "installed":
+		return installed
+	case "not-installed":
+		return !installed
+	}
+	return true
+}
+
+// installedBadge is appended to an entry's title in the menus.
+func installedBadge(reqs installer.Requirements, e store.Entry) string {
+	version, ok := installedVersion(reqs, e)
+	if !ok {
+		return ""
+	}
+	return " [installed " + version + "]"
+}
@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/skye-lopez/go-get-cli/store"
+)
+
+// tempModule writes a go.mod requiring the given modules to a temporary directory.
+func tempModule(t *testing.T, requires ...string) string {
+	t.Helper()
+	dir := t.TempDir()
+	goMod := "module example.com/app\n\ngo 1.23\n"
+	for _, r := range requires {
+		goMod += "\nrequire " + r + "\n"
+	}
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte(goMod), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+// withTarget points targetDir at a temporary module requiring the given modules, for the length of the test.
+func withTarget(t *testing.T, requires ...string) {
+	t.Helper()
+	previous := targetDir
+	targetDir = tempModule(t, requires...)
+	t.Cleanup(func() { targetDir = previous })
+}
+
+func TestInstalledBadge(t *testing.T) {
+	withTarget(t, "github.com/spf13/cobra v1.8.1", "github.com/jackc/pgx/v5 v5.7.1")
+	reqs := requiredModules()
+
+	tests := []struct {
+		link  string
+		badge string
+	}{
+		{"https://github.com/spf13/cobra", " [installed v1.8.1]"},
+		{"https://github.com/jackc/pgx", " [installed v5.7.1]"},
+		{"https://github.com/jackc/pgx/tree/master/pgxpool", " [installed v5.7.1]"},
+		{"https://github.com/spf13/viper", ""},
+		{"https://en.wikipedia.org/wiki/Go_(programming_language)", ""},
+	}
+	for _, tt := range tests {
+		if got := installedBadge(reqs, store.Entry{Link: tt.link}); got != tt.badge {
+			t.Errorf("installedBadge(%s) = %q, want %q", tt.link, got, tt.badge)
+		}
+	}
+}
+
+func TestInstalledOutsideModule(t *testing.T) {
+	previous := targetDir
+	targetDir = t.TempDir()
+	defer func() { targetDir = previous }()
+
+	if reqs := requiredModules(); len(reqs) != 0 {
+		t.Errorf("outside a module got %v, want nothing installed", reqs)
+	}
+}
+
+func TestShowEntry(t *testing.T) {
+	withTarget(t, "github.com/spf13/cobra v1.8.1")
+	reqs := requiredModules()
+	installed := store.Entry{Link: "https://github.com/spf13/cobra"}
+	missing := store.Entry{Link: "https://github.com/spf13/viper"}
+	defer func() { onlyFlag = "" }()
+
+	tests := []struct {
+		only      string
+		installed bool
+		missing   bool
+	}{
+		{"", true, true},
+		{"installed", true, false},
+		{"not-installed", false, true},
+	}
+	for _, tt := range tests {
+		onlyFlag = tt.only
+		if got := showEntry(reqs, installed); got != tt.installed {
+			t.Errorf("--only %q: showEntry(installed) = %v", tt.only, got)
+		}
+		if got := showEntry(reqs, missing); got != tt.missing {
+			t.Errorf("--only %q: showEntry(not installed) = %v", tt.only, got)
+		}
+	}
+}
+
+func TestSearchOnly(t *testing.T) {
+	withTarget(t, "github.com/jackc/pgx/v5 v5.7.1")
+	path := filepath.Join(t.TempDir(), "store.json")
+	defer func() { onlyFlag = "" }()
+
+	names := func() []string {
+		names := []string{}
+		for _, line := range strings.Split(strings.TrimSpace(stdout.String()), "\n") {
+			name, _, _ := strings.Cut(line, "\t")
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return names
+	}
+
+	if err := run(t, "search", "pgx", "--format", "tsv", "--only", "installed", "--store", path, "--source", fixture); err != nil {
+		t.Fatal(err)
+	}
+	if got := names(); strings.Join(got, ",") != "pgx,pgxpool" {
+		t.Errorf("--only installed printed %q, want pgx and pgxpool", got)
+	}
+
+	if err := run(t, "search", "driver", "--format", "tsv", "--only", "not-installed", "--store", path, "--source", fixture); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range names() {
+		if name == "pgx" {
+			t.Errorf("--only not-installed printed pgx: %q", names())
+		}
+	}
+	if len(names()) == 0 || names()[0] == "" {
+		t.Errorf("--only not-installed printed nothing")
+	}
+
+	if err := run(t, "search", "pgx", "--only", "some", "--store", path, "--source", fixture); err == nil {
+		t.Errorf("--only some: want an error")
+	}
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/skye-lopez/go-get-cli/interaction"
+)
+
+// KeymapEnv overrides the default --keymap.
+const KeymapEnv = "GO_GET_CLI_KEYMAP"
+
+var (
+	keymapFlag string
+	bindFlag   []string
+)
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&keymapFlag, "keymap", defaultKeymap(), "Keys for the menus: "+strings.Join(interaction.KeymapNames(), ", ")+" (default can be set with $"+KeymapEnv+")")
+	rootCmd.PersistentFlags().StringArrayVar(&bindFlag, "bind", nil, "Rebind a key in the menus, e.g. --bind ctrl+j=down or --bind q=none")
+}
+
+func defaultKeymap() string {
+	if name := os.Getenv(KeymapEnv); name != "" {
+		return name
+	}
+	return "default"
+}
+
+// menuContext is what go commands started from a menu run under. openMenu cancels it once the menu is
+// left, so an install still running when the menu is interrupted is stopped rather than orphaned.
+var menuContext = context.Background()
+
+// openMenu runs a menu until it is exited or interrupted.
+func openMenu(ctx context.Context, i *interaction.Interaction) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	menuContext = ctx
+	return i.Open()
+}
+
+// newInteraction sets up a menu with the keys asked for and installing the selection as its batch action.
+func newInteraction() (*interaction.Interaction, error) {
+	preset, ok := interaction.Keymaps[keymapFlag]
+	if !ok {
+		return nil, fmt.Errorf("unknown --keymap %q, expected one of %s", keymapFlag, strings.Join(interaction.KeymapNames(), ", "))
+	}
+	keymap := preset()
+	for _, binding := range bindFlag {
+		if err := keymap.Bind(binding); err != nil {
+			return nil, fmt.Errorf("--bind: %w", err)
+		}
+	}
+
+	i := interaction.NewInteraction()
+	i.Keymap = keymap
+	i.BatchAction = func(selected []*interaction.Option) (string, error) {
+		return batchInstall(menuContext, i.Status, selected)
+	}
+	return i, nil
+}
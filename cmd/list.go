@@ -2,11 +2,11 @@ package cmd
 
 import (
 	"fmt"
-	"os/exec"
 	"sort"
-	"strings"
 
+	"github.com/skye-lopez/go-get-cli/installer"
 	"github.com/skye-lopez/go-get-cli/interaction"
+	"github.com/skye-lopez/go-get-cli/store"
 	"github.com/spf13/cobra"
 )
 
@@ -25,17 +25,27 @@ var listCommand = &cobra.Command{
     ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
     `,
 
-	Run: list,
+	Annotations: map[string]string{catalogAnnotation: catalogLoad},
+	RunE:        list,
 }
 
 func init() {
 	rootCmd.AddCommand(listCommand)
 	listCommand.Flags().BoolP("categories", "c", false, "List all available categories and their subprojects")
 	listCommand.Flags().BoolP("all", "a", false, "List all available packages")
+	addOnlyFlag(listCommand)
 }
 
 // TODO: SHOW CURRENT PAGE DURING PAGINATED REQUESTS
-func list(cmd *cobra.Command, args []string) {
+func list(cmd *cobra.Command, args []string) error {
+	if err := checkOnlyFlag(); err != nil {
+		return err
+	}
+
+	// Reported on the way out so the first render doesn't wipe it
+	defer checkStale(cmd.ErrOrStderr())()
+	reqs := requiredModules()
+
 	categories, _ := cmd.Flags().GetBool("categories")
 	all, _ := cmd.Flags().GetBool("all")
 
@@ -49,104 +59,96 @@ func list(cmd *cobra.Command, args []string) {
 	}
 
 	if all {
-		i := interaction.NewInteraction()
-		homePrompt := i.CreatePrompt("All packages (sorted by name)", "[n] Next Page | [b] Last Page | [esc] Exit | [enter] Select", true)
+		i, err := newInteraction()
+		if err != nil {
+			return err
+		}
+		homePrompt := i.CreatePrompt("All packages (sorted by name)", "", true)
 
 		for _, v := range data.Entries {
-			if len(v.Name) <= 0 {
+			if len(v.Name) <= 0 || !showEntry(reqs, v) {
 				continue
 			}
 
-			option := homePrompt.AddOption(v.Name+" [category: "+v.Category+"]", v.Description, v)
-			entryPrompt := i.CreatePrompt(v.Name+"( "+v.Description+" )", "[enter] Select | [u] Back to list | [esc] Exit", false)
+			option := homePrompt.AddOption(v.Name+" [category: "+v.Category+"]"+installedBadge(reqs, v), v.Description, v)
+			option.Selectable = true
+			entryPrompt := i.CreatePrompt(v.Name+"( "+v.Description+" )", "", false)
+			entryPrompt.List.Labels = map[interaction.Action]string{interaction.ActionBack: "Back to list"}
 			entryPrompt.AttachParent(homePrompt.Idx)
 			option.AttachPrompt(entryPrompt.Idx)
 
-			installOption := entryPrompt.AddOption("Install via go get (gitlab/github package only)", "go get "+v.Link, v)
-
-			installFunc := func(...any) (string, error) {
-				goPath, err := exec.LookPath("go")
-				// This likely means the user does not have a go PATH set to $PATH
-				if err != nil {
-					panic(err)
-				}
-
-				// Format link for install
-				// /usr/local/go/bin/go go get  https://github.com/guptarohit/asciigraph
-				var installCandidate string
-
-				if strings.Contains(v.Link, "https://") {
-					installCandidate = strings.Split(v.Link, "https://")[1]
-				}
-
-				install := exec.Command(goPath, "get", installCandidate)
-				err = install.Run()
-				if err != nil {
-					return "Error installing the selected package.", err
-				}
-				return "Package installed! Have fun :)", nil
-			}
-			installOption.AddCallback(installFunc)
+			addInstallOptions(i, entryPrompt, v)
 		}
 
-		i.Open()
+		return openMenu(cmd.Context(), i)
 	}
 
 	if categories {
-		i := interaction.NewInteraction()
-		homePrompt := i.CreatePrompt("Available packages by category:", "[n] Next page | [b] Last page | [esc] Exit | [enter] Select", true)
+		i, err := newInteraction()
+		if err != nil {
+			return err
+		}
+		homePrompt := i.CreatePrompt("Available packages by category:", "", true)
 
+		// Children are looked up by their parent as well, names are only unique within a super-category.
+		byParent := make(map[string]store.Category, len(data.Categories))
 		for _, v := range data.Categories {
-			// TODO: Some are empty fsr...
-			if v.Name == "" {
+			byParent[v.Parent+"\x00"+v.Name] = v
+		}
+
+		for _, v := range data.Categories {
+			if v.Parent != "" {
 				continue
 			}
-			option := homePrompt.AddOption(v.Name, v.Description, v)
+			option := homePrompt.AddOption(v.Name+categorySuffix(v), v.Description, v)
+			categoryPrompt := addCategoryPrompt(i, homePrompt, v, byParent, reqs)
+			option.AttachPrompt(categoryPrompt.Idx)
+		}
+
+		return openMenu(cmd.Context(), i)
+	}
+	return nil
+}
 
-			categoryPrompt := i.CreatePrompt(v.Name+" - Packages ("+v.Description+") ", "[n] Next page | [b] Last page | [enter] Select | [u] Back to categories | [esc] Exit", true)
-			categoryPrompt.AttachParent(homePrompt.Idx)
+// categorySuffix hints that a category has subcategories to drill into.
+func categorySuffix(c store.Category) string {
+	if len(c.Children) > 0 {
+		return " >"
+	}
+	return ""
+}
 
-			option.AttachPrompt(categoryPrompt.Idx)
+// addCategoryPrompt creates the prompt for a category, which lists its subcategories followed by its own packages.
+// Subcategories get their own prompts recursively, each one navigating back up to its parent.
+func addCategoryPrompt(i *interaction.Interaction, parent *interaction.Prompt, v store.Category, byParent map[string]store.Category, reqs installer.Requirements) *interaction.Prompt {
+	categoryPrompt := i.CreatePrompt(v.Name+" - Packages ("+v.Description+") ", "", true)
+	categoryPrompt.AttachParent(parent.Idx)
 
-			for _, ov := range v.Entries {
-				if len(ov.Name) < 2 {
-					continue
-				}
-				catOption := categoryPrompt.AddOption(ov.Name, ov.Description, ov)
-
-				entryPrompt := i.CreatePrompt(ov.Name+"( "+ov.Description+" )", "[enter] Select | [u] Back to category | [esc] Exit", false)
-				entryPrompt.AttachParent(categoryPrompt.Idx)
-
-				catOption.AttachPrompt(entryPrompt.Idx)
-
-				installOption := entryPrompt.AddOption("Install via go get (gitlab/github package only)", "go get "+ov.Link, ov)
-
-				installFunc := func(...any) (string, error) {
-					goPath, err := exec.LookPath("go")
-					// This likely means the user does not have a go PATH set to $PATH
-					if err != nil {
-						panic(err)
-					}
-
-					// Format link for install
-					// /usr/local/go/bin/go go get  https://github.com/guptarohit/asciigraph
-					var installCandidate string
-
-					if strings.Contains(ov.Link, "https://") {
-						installCandidate = strings.Split(ov.Link, "https://")[1]
-					}
-
-					install := exec.Command(goPath, "get", installCandidate)
-					err = install.Run()
-					if err != nil {
-						return "Error installing the selected package.", err
-					}
-					return "Package installed! Have fun :)", nil
-				}
-				installOption.AddCallback(installFunc)
-			}
+	for _, name := range v.Children {
+		child, ok := byParent[v.Name+"\x00"+name]
+		if !ok {
+			continue
 		}
+		childOption := categoryPrompt.AddOption(child.Name+categorySuffix(child), child.Description, child)
+		childPrompt := addCategoryPrompt(i, categoryPrompt, child, byParent, reqs)
+		childOption.AttachPrompt(childPrompt.Idx)
+	}
+
+	for _, ov := range v.Entries {
+		if len(ov.Name) < 2 || !showEntry(reqs, ov) {
+			continue
+		}
+		catOption := categoryPrompt.AddOption(ov.Name+installedBadge(reqs, ov), ov.Description, ov)
+		catOption.Selectable = true
 
-		i.Open()
+		entryPrompt := i.CreatePrompt(ov.Name+"( "+ov.Description+" )", "", false)
+		entryPrompt.List.Labels = map[interaction.Action]string{interaction.ActionBack: "Back to category"}
+		entryPrompt.AttachParent(categoryPrompt.Idx)
+
+		catOption.AttachPrompt(entryPrompt.Idx)
+
+		addInstallOptions(i, entryPrompt, ov)
 	}
+
+	return categoryPrompt
 }
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/skye-lopez/go-get-cli/store"
+	"github.com/spf13/cobra"
+)
+
+var refreshCommand = &cobra.Command{
+	Use:   "refresh",
+	Short: "Re-download the package catalog",
+	Long: `Re-download the package catalog if it changed upstream.
+    ~~~~Refresh from the last used source~~~~
+    go-get-cli refresh
+    ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
+
+    ~~~~~~Refresh from another source~~~~~~~~
+    go-get-cli refresh --source ./our-list.md
+    ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
+    `,
+
+	Annotations: map[string]string{catalogAnnotation: catalogPath},
+	RunE:        refresh,
+}
+
+func init() {
+	rootCmd.AddCommand(refreshCommand)
+}
+
+func refresh(cmd *cobra.Command, args []string) error {
+	// Only read to find out where the catalog came from, a broken one gets replaced all the same
+	store.ReadFile(storePath, &data)
+
+	changes, updated, err := store.Refresh(storePath, currentSource())
+	if err != nil {
+		return err
+	}
+
+	if !updated {
+		fmt.Println("Catalog is already up to date.")
+		return nil
+	}
+
+	fmt.Printf("Catalog refreshed.\n  entries:    +%d -%d ~%d\n  categories: +%d -%d ~%d\n",
+		changes.EntriesAdded, changes.EntriesRemoved, changes.EntriesChanged,
+		changes.CategoriesAdded, changes.CategoriesRemoved, changes.CategoriesChanged)
+	return nil
+}
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/skye-lopez/go-get-cli/installer"
+	"github.com/spf13/cobra"
+)
+
+var removeCommand = &cobra.Command{
+	Use:   "remove <name-or-path>...",
+	Short: "Remove packages from the current module",
+	Long: `Remove packages from go.mod, the same as go get pkg@none followed by go mod tidy.
+    ~~~~~~~~~~~~~~Remove a dependency~~~~~~~~~~~~~~
+    go-get-cli remove cobra
+    go-get-cli remove github.com/spf13/cobra --dir ./service
+    ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
+
+    NOTE: if source files still import the package they are listed and nothing is removed, since the build
+    would break. --force removes it anyway, skipping go mod tidy as it would only add the package back.
+    `,
+
+	Args:        cobra.MinimumNArgs(1),
+	Annotations: map[string]string{catalogAnnotation: catalogLoad},
+	RunE:        remove,
+}
+
+func init() {
+	rootCmd.AddCommand(removeCommand)
+	removeCommand.Flags().String("dir", "", "Directory of the module to remove from (default is the working directory)")
+	removeCommand.Flags().Bool("force", false, "Remove packages even if source files still import them")
+}
+
+func remove(cmd *cobra.Command, args []string) error {
+	dir, _ := cmd.Flags().GetString("dir")
+	force, _ := cmd.Flags().GetBool("force")
+
+	failed := 0
+	for _, arg := range args {
+		name, _, _ := strings.Cut(arg, "@")
+		path, err := lookupPath(name, false)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			failed += 1
+			continue
+		}
+
+		result, err := goInstaller.Remove(cmd.Context(), installer.Request{Path: path, Dir: dir, Force: force})
+		if errors.Is(err, installer.ErrStillImported) {
+			fmt.Fprintf(os.Stderr, "%s is still imported by:\n  %s\nRemoving it would break the build, use --force to remove it anyway.\n",
+				result.Module, strings.Join(result.StillImported, "\n  "))
+			failed += 1
+			continue
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			failed += 1
+			continue
+		}
+		fmt.Println(removedMessage(result))
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d packages could not be removed", failed, len(args))
+	}
+	return nil
+}
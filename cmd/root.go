@@ -1,8 +1,13 @@
 package cmd
 
 import (
+	"fmt"
+	"io"
 	"os"
+	"time"
 
+	"github.com/skye-lopez/go-get-cli/installer"
+	"github.com/skye-lopez/go-get-cli/resolver"
 	"github.com/skye-lopez/go-get-cli/store"
 	"github.com/spf13/cobra"
 )
@@ -13,16 +18,40 @@ var rootCmd = &cobra.Command{
 	Short: "Search and install go packages!",
 	Long: `Search and install go packages! 
     Usage: `,
+	PersistentPreRunE: loadStore,
+	SilenceErrors:     true,
+	SilenceUsage:      true,
 }
 
-var data store.Store
+var (
+	data       store.Store
+	storePath  string
+	sourceFlag string
+	storeFlag  string
+
+	ttlFlag         time.Duration
+	autoRefreshFlag bool
+)
+
+var (
+	versions = &installer.ProxyClient{}
+	// modules asks the proxy for newer major versions, which links to a repository never mention.
+	modules = &resolver.Resolver{Majors: versions}
+	// goInstaller runs every install, it is a variable so a fake can be swapped in.
+	goInstaller installer.Installer = installer.New()
+)
+
+// TTLEnv overrides the default --ttl.
+const TTLEnv = "GO_GET_CLI_TTL"
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
 	err := rootCmd.Execute()
 	if err != nil {
-		os.Exit(1)
+		message, code := explain(err)
+		fmt.Fprintln(os.Stderr, message)
+		os.Exit(code)
 	}
 }
 
@@ -32,10 +61,109 @@ func init() {
 	// will be global for your application.
 
 	// rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.go-get-cli.yaml)")
+	rootCmd.PersistentFlags().StringVar(&sourceFlag, "source", "", "Catalog to load packages from, a url or file path (default is the awesome-go README)")
+	rootCmd.PersistentFlags().DurationVar(&ttlFlag, "ttl", defaultTTL(), "How old the catalog can get before it is considered stale, 0 disables the check (default can be set with $"+TTLEnv+")")
+	rootCmd.PersistentFlags().BoolVar(&autoRefreshFlag, "auto-refresh", false, "Refresh a stale catalog in the background instead of printing a warning")
+	rootCmd.PersistentFlags().StringVar(&storeFlag, "store", "", "Catalog file to use (default is $"+store.PathEnv+" or go-get-cli/store.json in the user cache dir)")
+}
+
+// catalogAnnotation marks what a command needs of the catalog, commands without it (help, completion)
+// run without touching it at all.
+const catalogAnnotation = "catalog"
+
+const (
+	catalogLoad = "load" // Fetched if missing and read into data
+	catalogPath = "path" // Only storePath is set, for commands that replace the catalog
+)
+
+// loadStore runs before every command, once flags have been parsed.
+func loadStore(cmd *cobra.Command, args []string) error {
+	needs, ok := cmd.Annotations[catalogAnnotation]
+	if !ok {
+		return nil
+	}
+
+	path, err := store.Path(storeFlag)
+	if err != nil {
+		return fmt.Errorf("could not find a place to keep the catalog, try passing --store: %w", err)
+	}
+	storePath = path
+	if needs == catalogPath {
+		return nil
+	}
+
+	if err := store.Init(storePath, store.NewSource(sourceFlag)); err != nil {
+		return err
+	}
+	if err := store.ReadFile(storePath, &data); err != nil {
+		return err
+	}
 
-	// Cobra also supports local flags, which will only run
-	// when this action is called directly.
-	rootCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
-	store.Init()
-	store.ReadFile(&data)
+	// The cached catalog may be from another source, --source asks for this one so it is fetched now
+	if src := store.NewSource(sourceFlag); sourceFlag != "" && src.Location() != data.Meta.Source {
+		if _, _, err := store.Refresh(storePath, src); err != nil {
+			return err
+		}
+		data = store.Store{}
+		return store.ReadFile(storePath, &data)
+	}
+	return nil
+}
+
+func defaultTTL() time.Duration {
+	ttl, err := time.ParseDuration(os.Getenv(TTLEnv))
+	if err != nil {
+		return 7 * 24 * time.Hour
+	}
+	return ttl
+}
+
+// currentSource is --source if given, otherwise wherever the loaded catalog came from.
+func currentSource() store.Source {
+	if sourceFlag != "" {
+		return store.NewSource(sourceFlag)
+	}
+	return store.NewSource(data.Meta.Source)
+}
+
+// refreshTimeout is how long a command waits on the way out for a background refresh to finish.
+var refreshTimeout = 10 * time.Second
+
+// checkStale looks at the age of the loaded catalog. With --auto-refresh a stale catalog gets refreshed
+// in the background while the command runs, which only updates the file for the next run. Otherwise
+// there is a warning to print. Either way the returned func reports to w, and is meant to be deferred:
+// it waits up to refreshTimeout for the refresh so it isn't cut off half way by the process exiting.
+func checkStale(w io.Writer) func() {
+	if ttlFlag <= 0 || !data.Meta.Stale(ttlFlag, time.Now()) {
+		return func() {}
+	}
+
+	if autoRefreshFlag {
+		done := make(chan error, 1)
+		go func() {
+			_, _, err := store.Refresh(storePath, currentSource())
+			done <- err
+		}()
+		return func() {
+			select {
+			case err := <-done:
+				if err != nil {
+					fmt.Fprintf(w, "Could not refresh the package catalog, run `go-get-cli refresh` to try again.\n  %s\n", err)
+				}
+			case <-time.After(refreshTimeout):
+				fmt.Fprintf(w, "Gave up refreshing the package catalog after %s, run `go-get-cli refresh` to try again.\n", refreshTimeout)
+			}
+		}
+	}
+
+	var warning string
+	if data.Meta.FetchedAt.IsZero() {
+		warning = "The package catalog has no fetch time recorded, run `go-get-cli refresh` to update it."
+	} else {
+		age := time.Since(data.Meta.FetchedAt).Round(time.Hour)
+		warning = fmt.Sprintf("The package catalog is %s old, run `go-get-cli refresh` to update it.", age)
+	}
+	return func() {
+		fmt.Fprintln(w, warning)
+	}
 }
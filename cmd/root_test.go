@@ -0,0 +1,201 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/skye-lopez/go-get-cli/interaction"
+	"github.com/skye-lopez/go-get-cli/store"
+)
+
+var fixture = filepath.Join("..", "store", "testdata", "README.md")
+
+// stdout and stderr are what the last run wrote to standard output and standard error.
+var stdout, stderr bytes.Buffer
+
+// run executes the command line in args, with the flags it doesn't give back at their defaults.
+func run(t *testing.T, args ...string) error {
+	t.Helper()
+	sourceFlag, storeFlag = "", ""
+	ttlFlag, autoRefreshFlag = defaultTTL(), false
+	data = store.Store{}
+	stdout.Reset()
+	stderr.Reset()
+	rootCmd.SetArgs(args)
+	rootCmd.SetOut(&stdout)
+	rootCmd.SetErr(&stderr)
+	return rootCmd.Execute()
+}
+
+func TestHelpAndCompletionSkipTheCatalog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store.json")
+	missing := filepath.Join(t.TempDir(), "missing.md")
+
+	for _, args := range [][]string{
+		{"help", "--store", path, "--source", missing},
+		{"completion", "bash", "--store", path, "--source", missing},
+		{"list", "--help", "--store", path, "--source", missing},
+	} {
+		if err := run(t, args...); err != nil {
+			t.Errorf("%s: %v", strings.Join(args, " "), err)
+		}
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("no catalog should have been written")
+	}
+}
+
+func TestRefreshReplacesCorruptStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store.json")
+	if err := os.WriteFile(path, []byte("{not json"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := run(t, "search", "cobra", "--store", path, "--source", fixture); !errors.Is(err, store.ErrCorruptStore) {
+		t.Fatalf("search over a corrupt catalog: got %v, want ErrCorruptStore", err)
+	}
+	if err := run(t, "refresh", "--store", path, "--source", fixture); err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	var s store.Store
+	if err := store.ReadFile(path, &s); err != nil || len(s.Entries) == 0 {
+		t.Errorf("catalog not replaced: %v, %d entries", err, len(s.Entries))
+	}
+}
+
+func TestSourceFlagReplacesCachedCatalog(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "store.json")
+	ours := filepath.Join(dir, "ours.md")
+	if err := os.WriteFile(ours, []byte("## Ours\n\n- [ourlib](https://example.com/ourlib) - Only in our list.\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := run(t, "search", "cobra", "--store", path, "--source", fixture); err != nil {
+		t.Fatal(err)
+	}
+	if err := run(t, "search", "ourlib", "--store", path, "--source", ours); err != nil {
+		t.Fatal(err)
+	}
+	if len(data.Entries) != 1 || data.Entries[0].Name != "ourlib" {
+		t.Errorf("got %d entries, want the catalog from --source %s", len(data.Entries), ours)
+	}
+}
+
+func TestExplainFetch(t *testing.T) {
+	err := run(t, "search", "x", "--store", filepath.Join(t.TempDir(), "store.json"), "--source", filepath.Join(t.TempDir(), "missing.md"))
+	message, code := explain(err)
+	if code != exitFetch {
+		t.Errorf("exit code %d, want %d", code, exitFetch)
+	}
+	if strings.Contains(message, "offline") || !strings.Contains(message, "missing.md") {
+		t.Errorf("a missing local file should be explained as one, got:\n%s", message)
+	}
+}
+
+func TestExplainInterrupted(t *testing.T) {
+	tests := []struct {
+		err  error
+		code int
+	}{
+		{&interaction.InterruptedError{Signal: syscall.SIGINT, Key: "ctrl+c"}, 130},
+		{&interaction.InterruptedError{Signal: syscall.SIGINT}, 130},
+		{fmt.Errorf("menu: %w", &interaction.InterruptedError{Signal: syscall.SIGTERM}), 143},
+		{interaction.ErrInterrupted, exitInterrupted},
+	}
+	for _, tt := range tests {
+		if _, code := explain(tt.err); code != tt.code {
+			t.Errorf("explain(%v) exit code %d, want %d", tt.err, code, tt.code)
+		}
+	}
+}
+
+// staleStore writes a catalog from src to a temporary store that is a year old.
+func staleStore(t *testing.T, src string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "store.json")
+	if err := store.Init(path, store.NewSource(src)); err != nil {
+		t.Fatal(err)
+	}
+	var s store.Store
+	if err := store.ReadFile(path, &s); err != nil {
+		t.Fatal(err)
+	}
+	s.Meta.FetchedAt = time.Now().AddDate(-1, 0, 0)
+	if err := store.WriteFile(path, s); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestStaleWarning(t *testing.T) {
+	path := staleStore(t, fixture)
+	if err := run(t, "search", "cobra", "--store", path); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(stderr.String(), "run `go-get-cli refresh`") {
+		t.Errorf("no warning about the stale catalog, got %q", stderr.String())
+	}
+}
+
+func TestAutoRefreshFinishesBeforeExit(t *testing.T) {
+	path := staleStore(t, fixture)
+	if err := run(t, "search", "cobra", "--store", path, "--auto-refresh"); err != nil {
+		t.Fatal(err)
+	}
+	if stderr.Len() != 0 {
+		t.Errorf("nothing should be reported for a refresh that worked, got %q", stderr.String())
+	}
+
+	var s store.Store
+	if err := store.ReadFile(path, &s); err != nil {
+		t.Fatal(err)
+	}
+	if s.Meta.Stale(defaultTTL(), time.Now()) {
+		t.Errorf("catalog still stale after the command returned, fetched at %s", s.Meta.FetchedAt)
+	}
+}
+
+func TestAutoRefreshReportsFailure(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "gone.md")
+	readme, err := os.ReadFile(fixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(src, readme, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	path := staleStore(t, src)
+	os.Remove(src)
+
+	if err := run(t, "search", "cobra", "--store", path, "--auto-refresh"); err != nil {
+		t.Fatalf("a failed background refresh should not fail the search: %v", err)
+	}
+	if !strings.Contains(stderr.String(), "Could not refresh the package catalog") || !strings.Contains(stderr.String(), "gone.md") {
+		t.Errorf("refresh failure not reported, got %q", stderr.String())
+	}
+}
+
+func TestTargetModuleDescribedOnOpen(t *testing.T) {
+	i := interaction.NewInteraction()
+	home := i.CreatePrompt("Home", "", false)
+	entryPrompt := i.CreatePrompt("Entry", "", false)
+	entryPrompt.AttachParent(home.Idx)
+	addTargetOption(i, entryPrompt, store.Entry{Name: "cobra", Link: "https://github.com/spf13/cobra"})
+
+	target := entryPrompt.List.Options[0]
+	if target.Title != "Target module" {
+		t.Errorf("target module looked up before the entry was opened: %q", target.Title)
+	}
+	i.Navigate(entryPrompt.Idx)
+	if want := "Target module: github.com/skye-lopez/go-get-cli"; target.Title != want {
+		t.Errorf("Title = %q, want %q once opened", target.Title, want)
+	}
+}
@@ -1,87 +1,134 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
-	"os/exec"
+	"io"
 	"strings"
+	"text/tabwriter"
 
+	"github.com/skye-lopez/go-get-cli/index"
 	"github.com/skye-lopez/go-get-cli/interaction"
+	"github.com/skye-lopez/go-get-cli/store"
 	"github.com/spf13/cobra"
 )
 
 var searchCommand = &cobra.Command{
-	Use:   "search",
+	Use:   "search [term]",
 	Short: "Search for a specific package with options",
 	Long: `Search for a specific package
     Usage examples:
 
-    ~~~Search By Text~~~
-    go-get-cli search -t <YOUR_SEARCH_TERM_HERE>`,
+    ~~~~~~~~Start a search session~~~~~~~~
+    go-get-cli search
+    ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
 
-	Run: search,
+    ~~~Print matches and exit (scripts)~~~
+    go-get-cli search <YOUR_SEARCH_TERM_HERE>
+    go-get-cli search <YOUR_SEARCH_TERM_HERE> --format json | jq
+    go-get-cli search <YOUR_SEARCH_TERM_HERE> --format tsv | fzf
+    ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~`,
+
+	Annotations: map[string]string{catalogAnnotation: catalogLoad},
+	RunE:        search,
 }
 
 func init() {
 	rootCmd.AddCommand(searchCommand)
-	searchCommand.Flags().BoolP("search", "", true, "Start a search session.")
+	searchCommand.Flags().StringP("format", "f", "table", "Output format when searching for a term: table, json or tsv")
+	addOnlyFlag(searchCommand)
 }
 
-func search(cmd *cobra.Command, args []string) {
-	search, _ := cmd.Flags().GetBool("search")
-
-	if !search {
-		return
+func search(cmd *cobra.Command, args []string) error {
+	if err := checkOnlyFlag(); err != nil {
+		return err
 	}
 
-	s := interaction.NewSearchInteraction()
-	homePrompt := s.CreatePrompt(
-		"Search for a pacakge. Result will filter as you type.",
-		"[n] Next Page | [b] Last Page | [+] Select search bar | [=] Select results | [enter] Select prompt | [esc] Exit",
-		true)
+	// Reported on the way out so the first render doesn't wipe it
+	defer checkStale(cmd.ErrOrStderr())()
 
+	reqs := requiredModules()
+	entries := []store.Entry{}
 	for _, v := range data.Entries {
-		entryOption := homePrompt.AddOption(v.Name, v.Description+" [Category: "+v.Category+"]", v)
-		s.UpdateTrie(entryOption)
+		if showEntry(reqs, v) {
+			entries = append(entries, v)
+		}
+	}
+
+	if len(args) > 0 {
+		format, _ := cmd.Flags().GetString("format")
+		return printMatches(cmd.OutOrStdout(), matchEntries(entries, strings.Join(args, " ")), format)
+	}
 
-		entryPrompt := s.CreatePrompt(v.Name+"( "+v.Description+" )", "[enter] Select | [u] Back to list | [esc] Exit", true)
+	s, err := newInteraction()
+	if err != nil {
+		return err
+	}
+	homePrompt, results := s.CreateSearchPrompt("Search for a package! Results filter as you type.")
+
+	for _, v := range entries {
+		entryOption := homePrompt.AddOption(v.Name+installedBadge(reqs, v), v.Description+" [Category: "+v.Category+"]", v)
+		entryOption.Selectable = true
+		results.AddToIndex(entryOption, entryFields(v)...)
+
+		entryPrompt := s.CreatePrompt(v.Name+"( "+v.Description+" )", "", true)
+		entryPrompt.List.Labels = map[interaction.Action]string{interaction.ActionBack: "Back to list"}
 		entryPrompt.AttachParent(homePrompt.Idx)
 		entryOption.AttachPrompt(entryPrompt.Idx)
 
-		installOption := entryPrompt.AddOption("Install via go get (gitlab/github package only)", "go get "+v.Link, v)
-
-		installFunc := func(...any) (string, error) {
-			goPath, err := exec.LookPath("go")
-			// This likely means the user does not have a go PATH set to $PATH
-			if err != nil {
-				panic(err)
-			}
-
-			// Format link for install
-			// /usr/local/go/bin/go go get  https://github.com/guptarohit/asciigraph
-			var installCandidate string
-
-			if strings.Contains(v.Link, "https://") {
-				fmt.Println("befor error")
-				installCandidate = strings.Split(v.Link, "https://")[1]
-				fmt.Println("after error")
-			}
-
-			install := exec.Command(goPath, "get", installCandidate)
-			err = install.Run()
-			if err != nil {
-				return "Error installing the selected package.", err
-			}
-			return "Package installed! Have fun :)", nil
-		}
-		installOption.AddCallback(installFunc)
+		addInstallOptions(s, entryPrompt, v)
+	}
+
+	return openMenu(cmd.Context(), s)
+}
+
+// entryFields is what an entry is searchable by. A hit on the name counts the most.
+func entryFields(e store.Entry) []index.Field {
+	return []index.Field{
+		{Text: e.Name, Weight: 3},
+		{Text: e.Category, Weight: 1.5},
+		{Text: e.Description, Weight: 1},
+	}
+}
+
+// matchEntries ranks every entry matching the term, best match first.
+func matchEntries(entries []store.Entry, term string) []store.Entry {
+	idx := index.New()
+	for i, v := range entries {
+		idx.Add(i, entryFields(v)...)
+	}
+
+	matches := []store.Entry{}
+	for _, hit := range idx.Search(term) {
+		matches = append(matches, entries[hit.ID])
 	}
+	return matches
+}
 
-	s.StoreOptionsFromPrompt(homePrompt)
-	s.Open()
+// printMatches writes search results for scripts and pipes rather than people.
+func printMatches(w io.Writer, entries []store.Entry, format string) error {
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(entries)
+	case "tsv":
+		for _, v := range entries {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", tsvField(v.Name), tsvField(v.Category), tsvField(v.Link), tsvField(v.Description))
+		}
+		return nil
+	case "table":
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "NAME\tCATEGORY\tLINK\tDESCRIPTION")
+		for _, v := range entries {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", v.Name, v.Category, v.Link, v.Description)
+		}
+		return tw.Flush()
+	}
+	return fmt.Errorf("unknown --format %q, expected table, json or tsv", format)
+}
 
-	// What I want
-	// A search bar
-	// Results that get filtered per key press in...
-	//
-	// We may need to implement a custom interaction
+// tsvField keeps tabs and newlines inside a value from breaking up the columns.
+func tsvField(s string) string {
+	return strings.NewReplacer("\t", " ", "\n", " ", "\r", " ").Replace(s)
 }
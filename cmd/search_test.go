@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/skye-lopez/go-get-cli/store"
+)
+
+var matches = []store.Entry{
+	{Category: "Standard CLI", Name: "cobra", Link: "https://github.com/spf13/cobra", Description: "Commander for modern Go CLI interactions."},
+	{Category: "Standard CLI", Name: "urfave/cli", Link: "https://github.com/urfave/cli", Description: "Tabs\tand\nnewlines\r\nin a description."},
+}
+
+func TestPrintMatchesJSON(t *testing.T) {
+	var out bytes.Buffer
+	if err := printMatches(&out, matches, "json"); err != nil {
+		t.Fatal(err)
+	}
+	var got []store.Entry
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("not JSON: %v\n%s", err, out.String())
+	}
+	if !reflect.DeepEqual(got, matches) {
+		t.Errorf("got %+v, want %+v", got, matches)
+	}
+
+	out.Reset()
+	if err := printMatches(&out, []store.Entry{}, "json"); err != nil || out.String() != "[]\n" {
+		t.Errorf("no matches printed %q, %v, want an empty array", out.String(), err)
+	}
+}
+
+func TestPrintMatchesTSV(t *testing.T) {
+	var out bytes.Buffer
+	if err := printMatches(&out, matches, "tsv"); err != nil {
+		t.Fatal(err)
+	}
+	want := "cobra\tStandard CLI\thttps://github.com/spf13/cobra\tCommander for modern Go CLI interactions.\n" +
+		"urfave/cli\tStandard CLI\thttps://github.com/urfave/cli\tTabs and newlines  in a description.\n"
+	if out.String() != want {
+		t.Errorf("got\n%q\nwant\n%q", out.String(), want)
+	}
+}
+
+func TestPrintMatchesTable(t *testing.T) {
+	var out bytes.Buffer
+	if err := printMatches(&out, matches[:1], "table"); err != nil {
+		t.Fatal(err)
+	}
+	want := "NAME   CATEGORY      LINK                            DESCRIPTION\n" +
+		"cobra  Standard CLI  https://github.com/spf13/cobra  Commander for modern Go CLI interactions.\n"
+	if out.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", out.String(), want)
+	}
+}
+
+func TestPrintMatchesUnknownFormat(t *testing.T) {
+	var out bytes.Buffer
+	err := printMatches(&out, matches, "xml")
+	if err == nil || !strings.Contains(err.Error(), `unknown --format "xml"`) {
+		t.Errorf("got %v, want an unknown --format error", err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("printed %q for an unknown format", out.String())
+	}
+
+	path := filepath.Join(t.TempDir(), "store.json")
+	if err := run(t, "search", "cobra", "--format", "xml", "--store", path, "--source", fixture); err == nil {
+		t.Errorf("search --format xml: want an error")
+	}
+}
+
+func TestSearchPrintsMatches(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store.json")
+	if err := run(t, "search", "cobra", "--format", "tsv", "--store", path, "--source", fixture); err != nil {
+		t.Fatal(err)
+	}
+	name, _, _ := strings.Cut(stdout.String(), "\t")
+	if name != "cobra" {
+		t.Errorf("search cobra printed %q first, want cobra", stdout.String())
+	}
+}
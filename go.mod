@@ -2,15 +2,17 @@ module github.com/skye-lopez/go-get-cli
 
 go 1.23.0
 
-require github.com/spf13/cobra v1.8.1
+require (
+	github.com/buger/goterm v1.0.4
+	github.com/pkg/term v1.1.0
+	github.com/spf13/cobra v1.8.1
+	github.com/yuin/goldmark v1.7.8
+	golang.org/x/mod v0.24.0
+	golang.org/x/text v0.24.0
+)
 
 require (
-	github.com/buger/goterm v1.0.4 // indirect
-	github.com/inancgumus/screen v0.0.0-20190314163918-06e984b86ed3 // indirect
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
-	github.com/pkg/term v1.1.0 // indirect
 	github.com/spf13/pflag v1.0.5 // indirect
-	golang.org/x/crypto v0.26.0 // indirect
 	golang.org/x/sys v0.23.0 // indirect
-	golang.org/x/term v0.23.0 // indirect
 )
@@ -0,0 +1,228 @@
+package index
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Fuzzy scoring, loosely modeled on fzf.
+const (
+	scoreMatch        = 16
+	bonusBoundary     = 8 // Match at the start of a word, or a camelCase hump
+	bonusConsecutive  = 4
+	penaltyGap        = 1
+	scoreTypo         = 6 // Per pattern character, typo matches always rank below real subsequences
+	penaltyTypo       = 10
+	maxSpreadPerChar  = 2 // A subsequence spread out much wider than the pattern is noise, not a match
+	minTypoPatternLen = 4
+)
+
+// Match is a fuzzy match of a pattern against a piece of text.
+type Match struct {
+	Score     int
+	Positions []int // Rune offsets into the text that matched, for highlighting
+}
+
+// Fuzzy matches pattern against text ignoring case and spaces in the pattern.
+// The pattern's characters have to show up in text in order ("zerlog" matches "zerolog"), scoring
+// higher when they are consecutive or start words. Failing that a word in text within a small edit
+// distance of the pattern still matches, so typos like "cobar" find "cobra".
+func Fuzzy(pattern string, text string) (Match, bool) {
+	p := []rune(strings.ToLower(strings.Join(strings.Fields(pattern), "")))
+	t := []rune(text)
+	if len(p) == 0 || len(t) == 0 {
+		return Match{}, false
+	}
+
+	if m, ok := subsequence(p, t); ok {
+		return m, true
+	}
+	return typo(p, t)
+}
+
+// subsequence finds the tightest window of text containing the pattern in order and scores it.
+func subsequence(p []rune, t []rune) (Match, bool) {
+	lower := []rune(strings.ToLower(string(t)))
+	if len(lower) != len(t) {
+		// Lower casing changed the rune count, positions would be off
+		lower = t
+	}
+
+	// Forward pass finds where the first full match ends
+	pi := 0
+	end := -1
+	for ti := 0; ti < len(lower) && pi < len(p); ti++ {
+		if lower[ti] == p[pi] {
+			pi += 1
+			if pi == len(p) {
+				end = ti
+			}
+		}
+	}
+	if end < 0 {
+		return Match{}, false
+	}
+
+	// Backward pass from there finds the latest start, giving the shortest window
+	pi = len(p) - 1
+	start := end
+	for ti := end; ti >= 0 && pi >= 0; ti-- {
+		if lower[ti] == p[pi] {
+			pi -= 1
+			start = ti
+		}
+	}
+	if end-start+1 > len(p)*maxSpreadPerChar+1 {
+		return Match{}, false
+	}
+
+	// Forward again inside the window to collect positions and score
+	m := Match{Positions: make([]int, 0, len(p))}
+	pi = 0
+	run := 0
+	for ti := start; ti <= end && pi < len(p); ti++ {
+		if lower[ti] != p[pi] {
+			m.Score -= penaltyGap
+			run = 0
+			continue
+		}
+
+		m.Score += scoreMatch
+		if boundary(t, ti) {
+			m.Score += bonusBoundary
+		}
+		if run > 0 {
+			m.Score += bonusConsecutive * run
+		}
+		run += 1
+		m.Positions = append(m.Positions, ti)
+		pi += 1
+	}
+	return m, true
+}
+
+// typo looks for a word in text (or the start of one) within edit distance of the pattern.
+func typo(p []rune, t []rune) (Match, bool) {
+	if len(p) < minTypoPatternLen {
+		return Match{}, false
+	}
+	allowed := 1
+	if len(p) > 7 {
+		allowed = 2
+	}
+
+	best := Match{}
+	found := false
+	for _, w := range words(t) {
+		word := []rune(strings.ToLower(string(t[w[0]:w[1]])))
+		if len(word) != w[1]-w[0] {
+			continue
+		}
+
+		// Compare against the whole word, and its start so half typed words match too
+		candidates := [][]rune{word}
+		if len(word) > len(p) {
+			candidates = append(candidates, word[:len(p)])
+		}
+		for _, c := range candidates {
+			dist, aligned := distance(p, c)
+			if dist > allowed {
+				continue
+			}
+
+			score := scoreTypo*len(p) - penaltyTypo*dist
+			if found && score <= best.Score {
+				continue
+			}
+			best = Match{Score: score, Positions: make([]int, len(aligned))}
+			for i, pos := range aligned {
+				best.Positions[i] = w[0] + pos
+			}
+			found = true
+		}
+	}
+	return best, found
+}
+
+// distance is the optimal string alignment distance between a and b (Levenshtein plus
+// transpositions of neighbours), along with the offsets in b of the characters that lined up.
+func distance(a []rune, b []rune) (int, []int) {
+	d := make([][]int, len(a)+1)
+	for i := range d {
+		d[i] = make([]int, len(b)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+
+	// Walk back through the table to find which characters matched
+	aligned := []int{}
+	i, j := len(a), len(b)
+	for i > 0 && j > 0 {
+		switch {
+		case a[i-1] == b[j-1] && d[i][j] == d[i-1][j-1]:
+			aligned = append(aligned, j-1)
+			i, j = i-1, j-1
+		case i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] && d[i][j] == d[i-2][j-2]+1:
+			aligned = append(aligned, j-1, j-2)
+			i, j = i-2, j-2
+		case d[i][j] == d[i-1][j-1]+1:
+			i, j = i-1, j-1
+		case d[i][j] == d[i-1][j]+1:
+			i -= 1
+		default:
+			j -= 1
+		}
+	}
+	for l, r := 0, len(aligned)-1; l < r; l, r = l+1, r-1 {
+		aligned[l], aligned[r] = aligned[r], aligned[l]
+	}
+
+	return d[len(a)][len(b)], aligned
+}
+
+// words returns the [start, end) rune offsets of every word in t.
+func words(t []rune) [][2]int {
+	spans := [][2]int{}
+	start := -1
+	for i, r := range t {
+		inWord := unicode.IsLetter(r) || unicode.IsDigit(r)
+		if inWord && start < 0 {
+			start = i
+		}
+		if !inWord && start >= 0 {
+			spans = append(spans, [2]int{start, i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		spans = append(spans, [2]int{start, len(t)})
+	}
+	return spans
+}
+
+// boundary reports whether t[i] starts a word or a camelCase hump.
+func boundary(t []rune, i int) bool {
+	if i == 0 {
+		return true
+	}
+	prev, cur := t[i-1], t[i]
+	if !unicode.IsLetter(prev) && !unicode.IsDigit(prev) {
+		return true
+	}
+	return unicode.IsLower(prev) && unicode.IsUpper(cur)
+}
@@ -0,0 +1,122 @@
+package index
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFuzzy(t *testing.T) {
+	tests := []struct {
+		pattern   string
+		text      string
+		ok        bool
+		positions []int
+	}{
+		// Subsequences
+		{"zerlog", "zerolog", true, []int{0, 1, 2, 4, 5, 6}},
+		{"cobra", "cobra", true, []int{0, 1, 2, 3, 4}},
+		{"COB", "cobra", true, []int{0, 1, 2}},
+		{"go get", "go-get-cli", true, []int{0, 1, 3, 4, 5}},
+		{"gcli", "go-get-cli", true, []int{3, 7, 8, 9}}, // The tightest window, not the first g
+		// Typos, by edit distance against a word or its start
+		{"cobar", "cobra", true, []int{0, 1, 2, 3, 4}},
+		{"zeroolg", "zerolog", true, nil},
+		{"fibre", "fiber", true, nil},
+		{"logrsu", "sirupsen logrus", true, nil},
+		// Too far apart to mean anything: a window over maxSpreadPerChar per pattern character
+		{"ab", "a123b", true, []int{0, 4}},
+		{"ab", "a1234b", false, nil},
+		// Typos need at least minTypoPatternLen characters to go on
+		{"cbo", "cobra", false, nil},
+		{"cbor", "cobra", true, nil},
+		{"", "cobra", false, nil},
+		{"cobra", "", false, nil},
+		{"xyzzy", "cobra", false, nil},
+	}
+	for _, tt := range tests {
+		m, ok := Fuzzy(tt.pattern, tt.text)
+		if ok != tt.ok {
+			t.Errorf("Fuzzy(%q, %q) matched = %t, want %t", tt.pattern, tt.text, ok, tt.ok)
+			continue
+		}
+		if tt.positions != nil && !reflect.DeepEqual(m.Positions, tt.positions) {
+			t.Errorf("Fuzzy(%q, %q) positions = %v, want %v", tt.pattern, tt.text, m.Positions, tt.positions)
+		}
+		if ok && len(m.Positions) == 0 {
+			t.Errorf("Fuzzy(%q, %q) matched without anything to highlight", tt.pattern, tt.text)
+		}
+	}
+}
+
+func TestFuzzyScores(t *testing.T) {
+	score := func(pattern string, text string) int {
+		t.Helper()
+		m, ok := Fuzzy(pattern, text)
+		if !ok {
+			t.Fatalf("Fuzzy(%q, %q) did not match", pattern, text)
+		}
+		return m.Score
+	}
+
+	// Consecutive characters beat scattered ones, word starts beat the middle of words
+	if a, b := score("cli", "go-cli"), score("cli", "cyclic"); a <= b {
+		t.Errorf("word start %d should beat mid word %d", a, b)
+	}
+	if a, b := score("log", "zerolog"), score("log", "lxoxg"); a <= b {
+		t.Errorf("consecutive %d should beat scattered %d", a, b)
+	}
+	if a, b := score("gg", "goGet"), score("gg", "bigger"); a <= b {
+		t.Errorf("camelCase hump %d should beat mid word %d", a, b)
+	}
+	// A typo always ranks below a real subsequence of the same pattern
+	if a, b := score("cobra", "cxoxbxrxa"), score("cobar", "cobra"); a <= b {
+		t.Errorf("subsequence %d should beat typo %d", a, b)
+	}
+	// One typo beats two
+	if a, b := score("zerologgr", "zerologger"), score("zeroloqqer", "zerologger"); a <= b {
+		t.Errorf("one typo %d should beat two %d", a, b)
+	}
+}
+
+func TestFuzzyLowerCaseChangesLength(t *testing.T) {
+	// İ and the Kelvin sign take fewer bytes lower cased, positions are still runes of the text as given
+	tests := []struct {
+		pattern   string
+		text      string
+		positions []int
+	}{
+		{"stan", "İstanbul", []int{1, 2, 3, 4}},
+		{"istanbul", "İSTANBUL", []int{0, 1, 2, 3, 4, 5, 6, 7}},
+		{"kelvin", "\u212aelvin", []int{0, 1, 2, 3, 4, 5}},
+		{"bul", "ÇİSTANBUL", []int{6, 7, 8}},
+		{"istnbul", "İstanbul", []int{0, 1, 2, 4, 5, 6, 7}}, // By typo
+		{"citi", "İstanbul city", []int{9, 10, 11}},
+	}
+	for _, tt := range tests {
+		m, ok := Fuzzy(tt.pattern, tt.text)
+		if !ok || !reflect.DeepEqual(m.Positions, tt.positions) {
+			t.Errorf("Fuzzy(%q, %q) = %v, %t, want positions %v", tt.pattern, tt.text, m.Positions, ok, tt.positions)
+		}
+	}
+}
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		a, b    string
+		dist    int
+		aligned []int
+	}{
+		{"cobra", "cobra", 0, []int{0, 1, 2, 3, 4}},
+		{"cobar", "cobra", 1, []int{0, 1, 2, 3, 4}}, // Transposed neighbours are one edit
+		{"zerlog", "zerolog", 1, []int{0, 1, 2, 4, 5, 6}},
+		{"fibre", "fiber", 1, []int{0, 1, 2, 3, 4}},
+		{"kitten", "sitting", 3, []int{1, 2, 3, 5}},
+		{"", "abc", 3, []int{}},
+	}
+	for _, tt := range tests {
+		dist, aligned := distance([]rune(tt.a), []rune(tt.b))
+		if dist != tt.dist || !reflect.DeepEqual(aligned, tt.aligned) {
+			t.Errorf("distance(%q, %q) = %d, %v, want %d, %v", tt.a, tt.b, dist, aligned, tt.dist, tt.aligned)
+		}
+	}
+}
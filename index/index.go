@@ -0,0 +1,181 @@
+// Package index is a small in-memory full text index, used to rank search results.
+// Documents are made of weighted fields and scored with BM25 (BM25F really, since
+// field weights scale the term frequencies).
+package index
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// BM25 tuning, these are the usual defaults.
+const (
+	k1 = 1.2
+	b  = 0.75
+)
+
+// Prefix matches (typing "postg" for "postgres") count for less than whole words.
+const prefixDiscount = 0.6
+
+// Field is a piece of text in a document. Matches in heavier fields rank higher.
+type Field struct {
+	Text   string
+	Weight float64
+}
+
+// Hit is a matching document, highest Score first.
+type Hit struct {
+	ID        int
+	Score     float64
+	Positions []int // Runes of the document's name that fuzzy matched the query, if any
+}
+
+type Index struct {
+	postings map[string]map[int]float64 // term -> document -> weighted term frequency
+	lengths  map[int]float64            // document -> weighted length
+	names    map[int]string             // document -> first field, what fuzzy matching runs against
+	totalLen float64
+	terms    []string // every term, sorted, for prefix lookups
+	dirty    bool     // terms needs sorting
+}
+
+func New() *Index {
+	return &Index{
+		postings: make(map[string]map[int]float64),
+		lengths:  make(map[int]float64),
+		names:    make(map[int]string),
+		terms:    make([]string, 0),
+	}
+}
+
+// Add indexes a document under id. Adding the same id twice adds to what is already there.
+// The first field is treated as the document's name, which is also matched fuzzily.
+func (x *Index) Add(id int, fields ...Field) {
+	if _, ok := x.names[id]; !ok && len(fields) > 0 {
+		x.names[id] = fields[0].Text
+	}
+	for _, f := range fields {
+		for _, term := range Tokenize(f.Text) {
+			docs, ok := x.postings[term]
+			if !ok {
+				docs = make(map[int]float64)
+				x.postings[term] = docs
+				x.terms = append(x.terms, term)
+				x.dirty = true
+			}
+			docs[id] += f.Weight
+			x.lengths[id] += f.Weight
+			x.totalLen += f.Weight
+		}
+	}
+}
+
+// Len is the number of documents in the index.
+func (x *Index) Len() int {
+	return len(x.lengths)
+}
+
+// Search returns every document that matches all the words in the query, best match first.
+// Words also match as prefixes so results can be shown while the user is still typing.
+// Documents whose name only fuzzy matches the query (typos, missing letters) come after those.
+func (x *Index) Search(query string) []Hit {
+	hits := x.fullText(query)
+
+	found := make(map[int]bool, len(hits))
+	for i := range hits {
+		found[hits[i].ID] = true
+		if m, ok := Fuzzy(query, x.names[hits[i].ID]); ok {
+			hits[i].Positions = m.Positions
+		}
+	}
+
+	fuzzy := []Hit{}
+	for id, name := range x.names {
+		if found[id] {
+			continue
+		}
+		if m, ok := Fuzzy(query, name); ok {
+			fuzzy = append(fuzzy, Hit{ID: id, Score: float64(m.Score), Positions: m.Positions})
+		}
+	}
+	sortHits(fuzzy)
+
+	return append(hits, fuzzy...)
+}
+
+func (x *Index) fullText(query string) []Hit {
+	words := Tokenize(query)
+	if len(words) == 0 || x.Len() == 0 {
+		return []Hit{}
+	}
+	if x.dirty {
+		sort.Strings(x.terms)
+		x.dirty = false
+	}
+
+	avgLen := x.totalLen / float64(x.Len())
+	var scores map[int]float64
+
+	for _, word := range words {
+		// Best score per document for this word, across the terms it expands to
+		wordScores := make(map[int]float64)
+
+		for i := sort.SearchStrings(x.terms, word); i < len(x.terms) && strings.HasPrefix(x.terms[i], word); i++ {
+			term := x.terms[i]
+			docs := x.postings[term]
+
+			discount := 1.0
+			if term != word {
+				discount = prefixDiscount
+			}
+
+			df := float64(len(docs))
+			idf := math.Log(1 + (float64(x.Len())-df+0.5)/(df+0.5))
+			for id, tf := range docs {
+				norm := tf + k1*(1-b+b*x.lengths[id]/avgLen)
+				score := discount * idf * tf * (k1 + 1) / norm
+				if score > wordScores[id] {
+					wordScores[id] = score
+				}
+			}
+		}
+
+		// Every word has to match
+		if scores == nil {
+			scores = wordScores
+			continue
+		}
+		for id := range scores {
+			if s, ok := wordScores[id]; ok {
+				scores[id] += s
+			} else {
+				delete(scores, id)
+			}
+		}
+	}
+
+	hits := make([]Hit, 0, len(scores))
+	for id, score := range scores {
+		hits = append(hits, Hit{ID: id, Score: score})
+	}
+	sortHits(hits)
+	return hits
+}
+
+func sortHits(hits []Hit) {
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].ID < hits[j].ID
+	})
+}
+
+// Tokenize lower cases text and splits it into words on anything that isn't a letter or digit.
+func Tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
@@ -0,0 +1,134 @@
+package index
+
+import (
+	"reflect"
+	"testing"
+)
+
+// entry is a catalog entry, indexed the way the search command does it.
+type entry struct {
+	name, category, description string
+}
+
+func build(entries []entry) *Index {
+	x := New()
+	for id, e := range entries {
+		x.Add(id,
+			Field{Text: e.name, Weight: 3},
+			Field{Text: e.category, Weight: 1.5},
+			Field{Text: e.description, Weight: 1},
+		)
+	}
+	return x
+}
+
+var catalog = []entry{
+	0: {"gorm", "ORM", "The fantastic ORM library for Golang."},
+	1: {"pgx", "Database Drivers", "PostgreSQL driver and toolkit."},
+	2: {"go-postgres", "Database Drivers", "Pure Go postgres driver for database/sql."},
+	3: {"ent", "ORM", "An entity framework for Go."},
+	4: {"orm", "Utilities", "Maps structs to rows."},
+	5: {"storm", "Database", "Simple and powerful toolkit for BoltDB."},
+	6: {"cobra", "Standard CLI", "Commander for modern Go CLI interactions."},
+	7: {"bun", "ORM", "SQL-first ORM for PostgreSQL, MySQL, MSSQL and SQLite."},
+}
+
+func ids(hits []Hit) []int {
+	out := make([]int, len(hits))
+	for i, h := range hits {
+		out[i] = h.ID
+	}
+	return out
+}
+
+func TestSearchOrder(t *testing.T) {
+	x := build(catalog)
+
+	tests := []struct {
+		query string
+		want  []int
+	}{
+		// The package called orm first, then ones filed under ORM, the one that also mentions it twice on top.
+		// storm only has the letters, so it comes after every full text hit.
+		{"orm", []int{4, 0, 7, 3, 5}},
+		// The whole word first, then prefixes of postgresql, the shorter description ranking higher
+		{"postgres", []int{2, 1, 7}},
+		// Every word has to match
+		{"postgres driver", []int{2, 1}},
+		{"orm sqlite", []int{7}},
+		// Prefixes match while typing
+		{"postg", []int{2, 1, 7}},
+		{"toolk", []int{1, 5}},
+		{"", []int{}},
+		{"nothing like it", []int{}},
+	}
+	for _, tt := range tests {
+		if got := ids(x.Search(tt.query)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Search(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestSearchFieldWeights(t *testing.T) {
+	x := build([]entry{
+		0: {"tool", "Other", "A queue."},
+		1: {"queue", "Other", "Does things."},
+		2: {"other", "Other", "Does things."},
+	})
+	if got := ids(x.Search("queue")); !reflect.DeepEqual(got, []int{1, 0}) {
+		t.Errorf("Search(queue) = %v, want the name hit before the description hit", got)
+	}
+}
+
+func TestSearchPrefixDiscount(t *testing.T) {
+	x := build([]entry{
+		0: {"a", "", "postgresql"},
+		1: {"b", "", "postgres"},
+	})
+	hits := x.Search("postgres")
+	if got := ids(hits); !reflect.DeepEqual(got, []int{1, 0}) {
+		t.Fatalf("Search(postgres) = %v, want the whole word first", got)
+	}
+	if ratio := hits[1].Score / hits[0].Score; ratio < prefixDiscount-0.01 || ratio > prefixDiscount+0.01 {
+		t.Errorf("prefix scored %.2f of the whole word, want %.2f", ratio, prefixDiscount)
+	}
+}
+
+func TestSearchFuzzyAfterFullText(t *testing.T) {
+	x := build(catalog)
+
+	hits := x.Search("orm")
+	last := hits[len(hits)-1]
+	if last.ID != 5 || last.Score <= hits[0].Score {
+		t.Errorf("storm should come last on a fuzzy score higher than any full text score, got %+v", hits)
+	}
+	if !reflect.DeepEqual(last.Positions, []int{2, 3, 4}) {
+		t.Errorf("storm highlights %v, want [2 3 4]", last.Positions)
+	}
+	// Full text hits highlight their name too, where it matches
+	if !reflect.DeepEqual(hits[0].Positions, []int{0, 1, 2}) || hits[3].Positions != nil {
+		t.Errorf("highlights %v and %v, want [0 1 2] for orm and none for ent", hits[0].Positions, hits[3].Positions)
+	}
+
+	// Typos only ever match fuzzily
+	if got := ids(x.Search("cobar")); !reflect.DeepEqual(got, []int{6}) {
+		t.Errorf("Search(cobar) = %v, want cobra", got)
+	}
+}
+
+func TestAddTwice(t *testing.T) {
+	x := New()
+	x.Add(1, Field{Text: "first", Weight: 1})
+	x.Add(1, Field{Text: "second", Weight: 1})
+	if x.Len() != 1 || len(x.Search("second")) != 1 || x.names[1] != "first" {
+		t.Errorf("adding to a document again should extend it, keeping its name")
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	got := Tokenize("Go-Get CLI: PostgreSQL/MySQL v2, naïve")
+	want := []string{"go", "get", "cli", "postgresql", "mysql", "v2", "naïve"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Tokenize = %q, want %q", got, want)
+	}
+}
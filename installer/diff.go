@@ -0,0 +1,126 @@
+package installer
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"golang.org/x/mod/modfile"
+)
+
+// ErrNothingToUndo means a Result has no record of the files it changed.
+var ErrNothingToUndo = errors.New("nothing to undo")
+
+// RequireChange is a require line go get added, removed or changed the version of.
+type RequireChange struct {
+	Path     string
+	Old      string // Empty when the requirement was added
+	New      string // Empty when the requirement was removed
+	Indirect bool
+}
+
+func (c RequireChange) Added() bool {
+	return c.Old == ""
+}
+
+func (c RequireChange) Removed() bool {
+	return c.New == ""
+}
+
+// snapshot is a copy of a module's go.mod and go.sum, nil contents meaning the file did not exist.
+type snapshot struct {
+	dir   string
+	goMod []byte
+	goSum []byte
+}
+
+func takeSnapshot(dir string) (*snapshot, error) {
+	s := &snapshot{dir: dir}
+	var err error
+	if s.goMod, err = readIfExists(filepath.Join(dir, "go.mod")); err != nil {
+		return nil, err
+	}
+	if s.goSum, err = readIfExists(filepath.Join(dir, "go.sum")); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *snapshot) restore() error {
+	if err := restoreFile(filepath.Join(s.dir, "go.mod"), s.goMod); err != nil {
+		return err
+	}
+	return restoreFile(filepath.Join(s.dir, "go.sum"), s.goSum)
+}
+
+// Undo puts go.mod and go.sum back the way they were before the install.
+func (r *Result) Undo() error {
+	if r == nil || r.snapshot == nil {
+		return ErrNothingToUndo
+	}
+	return r.snapshot.restore()
+}
+
+func readIfExists(path string) ([]byte, error) {
+	body, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	return body, err
+}
+
+func restoreFile(path string, body []byte) error {
+	if body == nil {
+		err := os.Remove(path)
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	return os.WriteFile(path, body, 0o644)
+}
+
+// requireChanges compares the require blocks of two go.mod files.
+// Unparseable files count as having no requirements.
+func requireChanges(before []byte, after []byte) []RequireChange {
+	type require struct {
+		version  string
+		indirect bool
+	}
+	requires := func(body []byte) map[string]require {
+		out := make(map[string]require)
+		f, err := modfile.ParseLax("go.mod", body, nil)
+		if err != nil {
+			return out
+		}
+		for _, r := range f.Require {
+			out[r.Mod.Path] = require{version: r.Mod.Version, indirect: r.Indirect}
+		}
+		return out
+	}
+	old, fresh := requires(before), requires(after)
+
+	changes := []RequireChange{}
+	for path, r := range fresh {
+		prev, ok := old[path]
+		if ok && prev.version == r.version && prev.indirect == r.indirect {
+			continue
+		}
+		changes = append(changes, RequireChange{Path: path, Old: prev.version, New: r.version, Indirect: r.indirect})
+	}
+	for path, r := range old {
+		if _, ok := fresh[path]; !ok {
+			changes = append(changes, RequireChange{Path: path, Old: r.version, Indirect: r.indirect})
+		}
+	}
+
+	// Direct dependencies first, they are what the user asked for
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Indirect != changes[j].Indirect {
+			return !changes[i].Indirect
+		}
+		return changes[i].Path < changes[j].Path
+	})
+	return changes
+}
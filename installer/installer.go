@@ -0,0 +1,247 @@
+// Package installer adds catalog packages to a Go module by running the go command.
+//
+// The go command is run through a Runner, so anything driving an Installer can swap
+// in a fake one rather than needing a real toolchain and network.
+package installer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/mod/module"
+)
+
+// ErrNotMain means go install was asked to build a package that is a library rather than a command.
+var ErrNotMain = errors.New("not a main package, it can only be added to a module with go get")
+
+// ErrNoGo means the go command could not be found, most likely because it is not on $PATH.
+var ErrNoGo = errors.New("go command not found, is it on your $PATH?")
+
+// Runner runs a command in dir and hands back what it printed. Cancelling ctx stops the command.
+type Runner interface {
+	Run(ctx context.Context, dir string, name string, args ...string) (stdout []byte, stderr []byte, err error)
+}
+
+// ExecRunner runs commands for real.
+type ExecRunner struct{}
+
+func (ExecRunner) Run(ctx context.Context, dir string, name string, args ...string) ([]byte, []byte, error) {
+	var stdout, stderr bytes.Buffer
+	c := exec.CommandContext(ctx, name, args...)
+	c.Dir = dir
+	c.Stdout = &stdout
+	c.Stderr = &stderr
+	err := c.Run()
+	return stdout.Bytes(), stderr.Bytes(), err
+}
+
+// Request is a package to install.
+type Request struct {
+	Path    string // Import path, see the resolver package for getting one from a catalog link
+	Version string // Empty means latest
+	Dir     string // Directory of the module to install into, empty means the working directory
+	Force   bool   // Remove the module even if source files still import it
+}
+
+// Result is what an install (or removal) did.
+type Result struct {
+	Module   string          // Module the package belongs to
+	Version  string          // Version go get settled on
+	Stdout   string          // Output of the go command
+	Stderr   string          // Errors and progress of the go command, go get reports what it changed here
+	Requires []RequireChange // What changed in go.mod's require block, indirect dependencies included
+
+	StillImported []string // Files that still import a module that was just removed
+
+	snapshot *snapshot // go.mod and go.sum from before the install, for Undo
+}
+
+// Installer runs the go command on behalf of a user. Cancelling ctx stops whichever go command is running.
+type Installer interface {
+	// Get adds the package to a module with go get.
+	Get(ctx context.Context, req Request) (*Result, error)
+	// Install builds the package as a binary into $GOBIN with go install.
+	Install(ctx context.Context, req Request) (*Result, error)
+	// Init creates a new module in dir with go mod init.
+	Init(ctx context.Context, dir string, path string) (*Result, error)
+	// Remove drops the package's module from go.mod.
+	Remove(ctx context.Context, req Request) (*Result, error)
+}
+
+// GoInstaller is an Installer backed by the go command.
+type GoInstaller struct {
+	Runner Runner
+	GoBin  string // Path to the go command, looked up on $PATH when empty
+}
+
+func New() *GoInstaller {
+	return &GoInstaller{Runner: ExecRunner{}}
+}
+
+// Get runs go get for the requested package. A failed install still returns a Result so the go command's output can be shown.
+// It fails with ErrNoModule up front if there is no module for go get to modify.
+func (g *GoInstaller) Get(ctx context.Context, req Request) (*Result, error) {
+	goBin, err := g.goBin()
+	if err != nil {
+		return nil, err
+	}
+
+	mod, err := FindModule(req.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	target := req.Path
+	if req.Version != "" {
+		target += "@" + req.Version
+	}
+
+	before, err := takeSnapshot(mod.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	stdout, stderr, err := g.Runner.Run(ctx, req.Dir, goBin, "get", target)
+	result := &Result{
+		Stdout:   string(stdout),
+		Stderr:   string(stderr),
+		snapshot: before,
+	}
+	if err != nil {
+		return result, fmt.Errorf("go get %s: %w\n%s", target, err, strings.TrimSpace(result.Stderr))
+	}
+
+	result.Module, result.Version = chosenVersion(result.Stderr, req.Path)
+	after, _ := os.ReadFile(filepath.Join(mod.Dir, "go.mod"))
+	result.Requires = requireChanges(before.goMod, after)
+
+	return result, nil
+}
+
+// Install runs go install for the requested package, at the latest version unless one was asked for.
+// Tools often keep their main package under cmd/ rather than at the root of the repository, so when
+// the package turns out to be a library, <path>/cmd/<name> is tried before failing with ErrNotMain.
+func (g *GoInstaller) Install(ctx context.Context, req Request) (*Result, error) {
+	goBin, err := g.goBin()
+	if err != nil {
+		return nil, err
+	}
+
+	version := req.Version
+	if version == "" {
+		version = "latest"
+	}
+
+	result, err := g.install(ctx, req.Dir, goBin, req.Path, version)
+	if !errors.Is(err, ErrNotMain) || strings.Contains(req.Path+"/", "/cmd/") {
+		return result, err
+	}
+
+	prefix, _, _ := module.SplitPathVersion(req.Path)
+	cmdPath := req.Path + "/cmd/" + path.Base(prefix)
+	probe, probeErr := g.install(ctx, req.Dir, goBin, cmdPath, version)
+	if probeErr != nil {
+		result.Stderr += probe.Stderr
+		return result, fmt.Errorf("%w: %s, and %s is not a command either", ErrNotMain, req.Path, cmdPath)
+	}
+	return probe, nil
+}
+
+// install runs go install path@version.
+func (g *GoInstaller) install(ctx context.Context, dir string, goBin string, path string, version string) (*Result, error) {
+	target := path + "@" + version
+	stdout, stderr, err := g.Runner.Run(ctx, dir, goBin, "install", target)
+	result := &Result{
+		Stdout: string(stdout),
+		Stderr: string(stderr),
+	}
+	if err != nil {
+		if strings.Contains(result.Stderr, "not a main package") {
+			return result, fmt.Errorf("%w: %s", ErrNotMain, path)
+		}
+		return result, fmt.Errorf("go install %s: %w\n%s", target, err, strings.TrimSpace(result.Stderr))
+	}
+
+	result.Module = path
+	result.Version = version
+	return result, nil
+}
+
+// Init runs go mod init, creating a module called path in dir.
+func (g *GoInstaller) Init(ctx context.Context, dir string, path string) (*Result, error) {
+	goBin, err := g.goBin()
+	if err != nil {
+		return nil, err
+	}
+
+	stdout, stderr, err := g.Runner.Run(ctx, dir, goBin, "mod", "init", path)
+	result := &Result{
+		Module: path,
+		Stdout: string(stdout),
+		Stderr: string(stderr),
+	}
+	if err != nil {
+		return result, fmt.Errorf("go mod init %s: %w\n%s", path, err, strings.TrimSpace(result.Stderr))
+	}
+	return result, nil
+}
+
+// LooksLikeCommand guesses whether a package is a command line tool rather than a library, from its
+// import path and catalog category. There is no telling for sure without downloading it, so this is
+// only used to decide which install option to offer first.
+func LooksLikeCommand(path string, category string) bool {
+	if strings.Contains(path+"/", "/cmd/") {
+		return true
+	}
+	category = strings.ToLower(category)
+	for _, hint := range []string{"tool", "software", "code analysis"} {
+		if strings.Contains(category, hint) {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *GoInstaller) goBin() (string, error) {
+	if g.GoBin != "" {
+		return g.GoBin, nil
+	}
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrNoGo, err)
+	}
+	return goBin, nil
+}
+
+// chosenVersion finds the module and version go get reported for path, from lines like
+//
+//	go: added github.com/spf13/cobra v1.8.1
+//	go: upgraded github.com/spf13/pflag v1.0.5 => v1.0.6
+//
+// The module is the longest one path is inside of, nothing is returned if it was already required.
+func chosenVersion(stderr string, path string) (string, string) {
+	var module, version string
+	for _, line := range strings.Split(stderr, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 4 || fields[0] != "go:" || (fields[1] != "added" && fields[1] != "upgraded" && fields[1] != "downgraded") {
+			continue
+		}
+
+		mod := fields[2]
+		if path != mod && !strings.HasPrefix(path, mod+"/") {
+			continue
+		}
+		if len(mod) > len(module) {
+			module = mod
+			version = fields[len(fields)-1]
+		}
+	}
+	return module, version
+}
@@ -0,0 +1,429 @@
+package installer
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeRunner stands in for the go command. Every call is recorded, and run decides what it does.
+type fakeRunner struct {
+	calls []string
+	run   func(dir string, args []string) (stdout string, stderr string, err error)
+}
+
+func (f *fakeRunner) Run(ctx context.Context, dir string, name string, args ...string) ([]byte, []byte, error) {
+	f.calls = append(f.calls, strings.Join(args, " "))
+	if f.run == nil {
+		return nil, nil, nil
+	}
+	stdout, stderr, err := f.run(dir, args)
+	return []byte(stdout), []byte(stderr), err
+}
+
+const baseGoMod = `module example.com/app
+
+go 1.23
+
+require example.com/other v1.0.0
+`
+
+// tempModule writes a module with baseGoMod and the given files to a temporary directory.
+func tempModule(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	files["go.mod"] = baseGoMod
+	for name, body := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	body, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(body)
+}
+
+func TestChosenVersion(t *testing.T) {
+	tests := []struct {
+		stderr  string
+		path    string
+		module  string
+		version string
+	}{
+		{"go: added github.com/spf13/cobra v1.8.1\n", "github.com/spf13/cobra", "github.com/spf13/cobra", "v1.8.1"},
+		{"go: downloading github.com/spf13/cobra v1.8.1\ngo: added github.com/spf13/cobra v1.8.1\n", "github.com/spf13/cobra/doc", "github.com/spf13/cobra", "v1.8.1"},
+		{"go: upgraded github.com/spf13/pflag v1.0.5 => v1.0.6\n", "github.com/spf13/pflag", "github.com/spf13/pflag", "v1.0.6"},
+		{"go: downgraded example.com/mod v1.2.0 => v1.1.0\n", "example.com/mod", "example.com/mod", "v1.1.0"},
+		// Nested modules: the longest one the package is in wins
+		{"go: added example.com/mod v1.0.0\ngo: added example.com/mod/sub v0.3.0\n", "example.com/mod/sub/pkg", "example.com/mod/sub", "v0.3.0"},
+		// A module that only shares a prefix with the path is someone else's
+		{"go: added example.com/modx v1.0.0\n", "example.com/mod", "", ""},
+		// Already required, go get has nothing to report
+		{"", "example.com/mod", "", ""},
+		{"go: added example.com/mod\n", "example.com/mod", "", ""},
+	}
+	for _, tt := range tests {
+		module, version := chosenVersion(tt.stderr, tt.path)
+		if module != tt.module || version != tt.version {
+			t.Errorf("chosenVersion(%q, %q) = %q, %q, want %q, %q", tt.stderr, tt.path, module, version, tt.module, tt.version)
+		}
+	}
+}
+
+func TestGetAndUndo(t *testing.T) {
+	dir := tempModule(t, map[string]string{})
+	runner := &fakeRunner{run: func(dir string, args []string) (string, string, error) {
+		goMod := baseGoMod + "\nrequire example.com/mod v1.2.0\n\nrequire example.com/dep v0.1.0 // indirect\n"
+		os.WriteFile(filepath.Join(dir, "go.mod"), []byte(goMod), 0o644)
+		os.WriteFile(filepath.Join(dir, "go.sum"), []byte("example.com/mod v1.2.0 h1:abc=\n"), 0o644)
+		return "", "go: downloading example.com/mod v1.2.0\ngo: added example.com/mod v1.2.0\n", nil
+	}}
+	g := &GoInstaller{Runner: runner, GoBin: "go"}
+
+	result, err := g.Get(context.Background(), Request{Path: "example.com/mod/pkg", Version: "v1.2.0", Dir: dir})
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if want := []string{"get example.com/mod/pkg@v1.2.0"}; !reflect.DeepEqual(runner.calls, want) {
+		t.Errorf("ran %q, want %q", runner.calls, want)
+	}
+	if result.Module != "example.com/mod" || result.Version != "v1.2.0" {
+		t.Errorf("got %s@%s, want example.com/mod@v1.2.0", result.Module, result.Version)
+	}
+	want := []RequireChange{
+		{Path: "example.com/mod", New: "v1.2.0"},
+		{Path: "example.com/dep", New: "v0.1.0", Indirect: true},
+	}
+	if !reflect.DeepEqual(result.Requires, want) {
+		t.Errorf("Requires = %+v, want %+v", result.Requires, want)
+	}
+
+	if err := result.Undo(); err != nil {
+		t.Fatalf("Undo: %v", err)
+	}
+	if got := readFile(t, filepath.Join(dir, "go.mod")); got != baseGoMod {
+		t.Errorf("go.mod after Undo:\n%s\nwant\n%s", got, baseGoMod)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "go.sum")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("go.sum did not exist before, Undo should remove it")
+	}
+}
+
+func TestGetFails(t *testing.T) {
+	dir := tempModule(t, map[string]string{})
+	runner := &fakeRunner{run: func(dir string, args []string) (string, string, error) {
+		return "", "go: example.com/mod@latest: module not found\n", errors.New("exit status 1")
+	}}
+	g := &GoInstaller{Runner: runner, GoBin: "go"}
+
+	result, err := g.Get(context.Background(), Request{Path: "example.com/mod", Dir: dir})
+	if err == nil || !strings.Contains(err.Error(), "module not found") {
+		t.Errorf("got %v, want the go command's error", err)
+	}
+	if result == nil || !strings.Contains(result.Stderr, "module not found") {
+		t.Errorf("a failed install should still return the go command's output")
+	}
+	if want := []string{"get example.com/mod"}; !reflect.DeepEqual(runner.calls, want) {
+		t.Errorf("ran %q, want %q", runner.calls, want)
+	}
+}
+
+func TestGetNoModule(t *testing.T) {
+	runner := &fakeRunner{}
+	g := &GoInstaller{Runner: runner, GoBin: "go"}
+
+	if _, err := g.Get(context.Background(), Request{Path: "example.com/mod", Dir: t.TempDir()}); !errors.Is(err, ErrNoModule) {
+		t.Errorf("got %v, want ErrNoModule", err)
+	}
+	if len(runner.calls) != 0 {
+		t.Errorf("ran %q without a module to install into", runner.calls)
+	}
+}
+
+func TestNoGo(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	runner := &fakeRunner{}
+	g := &GoInstaller{Runner: runner}
+	dir := tempModule(t, map[string]string{})
+	req := Request{Path: "example.com/other", Dir: dir}
+
+	if _, err := g.Get(context.Background(), req); !errors.Is(err, ErrNoGo) {
+		t.Errorf("Get: got %v, want ErrNoGo", err)
+	}
+	if _, err := g.Install(context.Background(), req); !errors.Is(err, ErrNoGo) {
+		t.Errorf("Install: got %v, want ErrNoGo", err)
+	}
+	if _, err := g.Remove(context.Background(), req); !errors.Is(err, ErrNoGo) {
+		t.Errorf("Remove: got %v, want ErrNoGo", err)
+	}
+	if len(runner.calls) != 0 {
+		t.Errorf("ran %q without a go command", runner.calls)
+	}
+}
+
+func TestInstall(t *testing.T) {
+	runner := &fakeRunner{}
+	g := &GoInstaller{Runner: runner, GoBin: "go"}
+
+	result, err := g.Install(context.Background(), Request{Path: "example.com/tool/cmd/tool"})
+	if err != nil {
+		t.Fatalf("Install: %v", err)
+	}
+	if result.Version != "latest" || runner.calls[0] != "install example.com/tool/cmd/tool@latest" {
+		t.Errorf("ran %q, got version %q, want the latest", runner.calls, result.Version)
+	}
+
+	runner.run = func(dir string, args []string) (string, string, error) {
+		return "", "package example.com/lib is not a main package\n", errors.New("exit status 1")
+	}
+	if _, err := g.Install(context.Background(), Request{Path: "example.com/lib", Version: "v1.0.0"}); !errors.Is(err, ErrNotMain) {
+		t.Errorf("got %v, want ErrNotMain", err)
+	}
+}
+
+// removingRunner rewrites go.mod the way go get mod@none would.
+func removingRunner() *fakeRunner {
+	return &fakeRunner{run: func(dir string, args []string) (string, string, error) {
+		if args[0] == "get" {
+			goMod := strings.Replace(baseGoMod, "require example.com/other v1.0.0\n", "", 1)
+			os.WriteFile(filepath.Join(dir, "go.mod"), []byte(goMod), 0o644)
+		}
+		return "", "", nil
+	}}
+}
+
+func TestRemoveAndUndo(t *testing.T) {
+	dir := tempModule(t, map[string]string{"main.go": "package main\n\nfunc main() {}\n"})
+	runner := removingRunner()
+	g := &GoInstaller{Runner: runner, GoBin: "go"}
+
+	result, err := g.Remove(context.Background(), Request{Path: "example.com/other/pkg", Dir: dir})
+	if err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	if want := []string{"get example.com/other@none", "mod tidy"}; !reflect.DeepEqual(runner.calls, want) {
+		t.Errorf("ran %q, want %q", runner.calls, want)
+	}
+	if want := []RequireChange{{Path: "example.com/other", Old: "v1.0.0"}}; result.Module != "example.com/other" || !reflect.DeepEqual(result.Requires, want) {
+		t.Errorf("got %s %+v, want example.com/other %+v", result.Module, result.Requires, want)
+	}
+
+	if err := result.Undo(); err != nil {
+		t.Fatalf("Undo: %v", err)
+	}
+	if got := readFile(t, filepath.Join(dir, "go.mod")); got != baseGoMod {
+		t.Errorf("go.mod after Undo:\n%s\nwant\n%s", got, baseGoMod)
+	}
+}
+
+func TestRemoveStillImported(t *testing.T) {
+	dir := tempModule(t, map[string]string{
+		"main.go":  "package main\n\nimport _ \"example.com/other/pkg\"\n\nfunc main() {}\n",
+		"other.go": "package main\n\nimport _ \"example.com/otherwise\"\n",
+	})
+	runner := removingRunner()
+	g := &GoInstaller{Runner: runner, GoBin: "go"}
+
+	result, err := g.Remove(context.Background(), Request{Path: "example.com/other", Dir: dir})
+	if !errors.Is(err, ErrStillImported) {
+		t.Fatalf("got %v, want ErrStillImported", err)
+	}
+	if len(runner.calls) != 0 {
+		t.Errorf("ran %q before the removal was confirmed", runner.calls)
+	}
+	if want := []string{"main.go"}; !reflect.DeepEqual(result.StillImported, want) {
+		t.Errorf("StillImported = %q, want %q", result.StillImported, want)
+	}
+
+	result, err = g.Remove(context.Background(), Request{Path: "example.com/other", Dir: dir, Force: true})
+	if err != nil {
+		t.Fatalf("Remove with Force: %v", err)
+	}
+	if want := []string{"get example.com/other@none"}; !reflect.DeepEqual(runner.calls, want) {
+		t.Errorf("ran %q, want %q without tidying the module straight back in", runner.calls, want)
+	}
+	if want := []string{"main.go"}; !reflect.DeepEqual(result.StillImported, want) {
+		t.Errorf("StillImported = %q, want %q", result.StillImported, want)
+	}
+}
+
+func TestImporters(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod": `module example.com/app
+
+go 1.23
+
+require (
+	example.com/a v1.0.0
+	example.com/a/v2 v2.0.0
+	example.com/a/service v0.1.0
+)
+`,
+		"a.go":       "package app\n\nimport _ \"example.com/a/pkg\"\n",
+		"v2.go":      "package app\n\nimport _ \"example.com/a/v2\"\n",
+		"service.go": "package app\n\nimport _ \"example.com/a/service/s3\"\n",
+	}
+	for name, body := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		path   string
+		module string
+		files  []string
+	}{
+		{"example.com/a", "example.com/a", []string{"a.go"}},
+		{"example.com/a/v2/pkg", "example.com/a/v2", []string{"v2.go"}},
+		{"example.com/a/service/s3", "example.com/a/service", []string{"service.go"}},
+	}
+	for _, tt := range tests {
+		module, files, err := Importers(dir, tt.path)
+		if err != nil {
+			t.Errorf("Importers(%q): %v", tt.path, err)
+			continue
+		}
+		if module != tt.module || !reflect.DeepEqual(files, tt.files) {
+			t.Errorf("Importers(%q) = %s %q, want %s %q", tt.path, module, files, tt.module, tt.files)
+		}
+	}
+
+	if _, _, err := Importers(dir, "example.com/b"); !errors.Is(err, ErrNotRequired) {
+		t.Errorf("got %v, want ErrNotRequired", err)
+	}
+}
+
+func TestRemoveNotRequired(t *testing.T) {
+	dir := tempModule(t, map[string]string{})
+	runner := removingRunner()
+	g := &GoInstaller{Runner: runner, GoBin: "go"}
+
+	if _, err := g.Remove(context.Background(), Request{Path: "example.com/otherwise", Dir: dir}); !errors.Is(err, ErrNotRequired) {
+		t.Errorf("got %v, want ErrNotRequired", err)
+	}
+	if len(runner.calls) != 0 {
+		t.Errorf("ran %q for a module that isn't required", runner.calls)
+	}
+}
+
+func TestUndoNothing(t *testing.T) {
+	var result *Result
+	if err := result.Undo(); !errors.Is(err, ErrNothingToUndo) {
+		t.Errorf("got %v, want ErrNothingToUndo", err)
+	}
+	if err := (&Result{}).Undo(); !errors.Is(err, ErrNothingToUndo) {
+		t.Errorf("got %v, want ErrNothingToUndo", err)
+	}
+}
+
+func TestInstallProbesCmd(t *testing.T) {
+	runner := &fakeRunner{run: func(dir string, args []string) (string, string, error) {
+		if args[1] == "example.com/golangci-lint/v2/cmd/golangci-lint@latest" {
+			return "", "", nil
+		}
+		return "", "package " + args[1] + " is not a main package\n", errors.New("exit status 1")
+	}}
+	g := &GoInstaller{Runner: runner, GoBin: "go"}
+
+	result, err := g.Install(context.Background(), Request{Path: "example.com/golangci-lint/v2"})
+	if err != nil {
+		t.Fatalf("Install: %v", err)
+	}
+	want := []string{"install example.com/golangci-lint/v2@latest", "install example.com/golangci-lint/v2/cmd/golangci-lint@latest"}
+	if !reflect.DeepEqual(runner.calls, want) {
+		t.Errorf("ran %q, want %q", runner.calls, want)
+	}
+	if result.Module != "example.com/golangci-lint/v2/cmd/golangci-lint" {
+		t.Errorf("installed %q, want the command under cmd/", result.Module)
+	}
+
+	// A library without a command under cmd/ still fails, and a path already in cmd/ isn't probed
+	tests := []struct {
+		path     string
+		installs int
+	}{
+		{"example.com/lib", 2},
+		{"example.com/tool/cmd/other", 1},
+	}
+	for _, tt := range tests {
+		runner.calls = nil
+		if _, err := g.Install(context.Background(), Request{Path: tt.path}); !errors.Is(err, ErrNotMain) {
+			t.Errorf("Install(%q): got %v, want ErrNotMain", tt.path, err)
+		}
+		if len(runner.calls) != tt.installs {
+			t.Errorf("Install(%q) ran %q, want %d go installs", tt.path, runner.calls, tt.installs)
+		}
+	}
+}
+
+func TestRequirementsVersion(t *testing.T) {
+	reqs := Requirements{
+		"github.com/spf13/cobra":       "v1.8.1",
+		"github.com/jackc/pgx/v5":      "v5.7.1",
+		"example.com/a":                "v1.0.0",
+		"example.com/a/v2":             "v2.1.0",
+		"example.com/a/service":        "v0.1.0",
+		"gopkg.in/yaml.v3":             "v3.0.1",
+		"github.com/go-sql-driver/sql": "v1.0.0",
+	}
+	tests := []struct {
+		path    string
+		version string
+		ok      bool
+	}{
+		{"github.com/spf13/cobra", "v1.8.1", true},
+		{"github.com/spf13/cobra/doc", "v1.8.1", true},
+		// Links point at the repository, which finds its v2+ module
+		{"github.com/jackc/pgx", "v5.7.1", true},
+		{"github.com/jackc/pgx/pgxpool", "v5.7.1", true},
+		{"github.com/jackc/pgx/v5", "v5.7.1", true},
+		{"github.com/jackc/pgx/v4", "", false},
+		// With v1 and v2 both required the newest major wins, nested modules still win over either
+		{"example.com/a", "v2.1.0", true},
+		{"example.com/a/v2/pkg", "v2.1.0", true},
+		{"example.com/a/service/s3", "v0.1.0", true},
+		{"gopkg.in/yaml.v3", "v3.0.1", true},
+		{"gopkg.in/yaml", "", false},
+		// Sharing a prefix is not enough
+		{"github.com/go-sql-driver/sqlite", "", false},
+		{"github.com/spf13", "", false},
+	}
+	for _, tt := range tests {
+		version, ok := reqs.Version(tt.path)
+		if version != tt.version || ok != tt.ok {
+			t.Errorf("Version(%q) = %q, %v, want %q, %v", tt.path, version, ok, tt.version, tt.ok)
+		}
+	}
+}
+
+func TestExecRunnerCancel(t *testing.T) {
+	sleep, err := exec.LookPath("sleep")
+	if err != nil {
+		t.Skip("no sleep command to run")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	if _, _, err := (ExecRunner{}).Run(ctx, t.TempDir(), sleep, "10"); err == nil {
+		t.Errorf("a cancelled command should fail")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("the command ran for %s after being cancelled", elapsed)
+	}
+}
@@ -0,0 +1,175 @@
+package installer
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+)
+
+// ErrNoModule means there is no go.mod in or above the directory an install was asked to run in.
+var ErrNoModule = errors.New("no go.mod found")
+
+// Target is the module an install modifies.
+type Target struct {
+	Dir    string // Directory holding go.mod
+	Path   string // Module path declared in go.mod
+	GoWork string // go.work in effect, if any
+}
+
+// FindModule walks up from dir to the nearest go.mod, the same one the go command would use.
+// When there is none it fails with ErrNoModule, but still fills in GoWork so a caller can offer
+// the workspace's modules instead.
+func FindModule(dir string) (Target, error) {
+	if dir == "" {
+		dir = "."
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return Target{}, err
+	}
+
+	t := Target{GoWork: findGoWork(abs)}
+	goMod, ok := findUp(abs, "go.mod")
+	if !ok {
+		return t, fmt.Errorf("%w in %s or any directory above it", ErrNoModule, abs)
+	}
+
+	path, err := modulePath(goMod)
+	if err != nil {
+		return t, err
+	}
+	t.Dir = filepath.Dir(goMod)
+	t.Path = path
+	return t, nil
+}
+
+// WorkspaceModules lists the modules a go.work file uses.
+func WorkspaceModules(goWork string) ([]Target, error) {
+	body, err := os.ReadFile(goWork)
+	if err != nil {
+		return nil, err
+	}
+	f, err := modfile.ParseWork(goWork, body, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	targets := []Target{}
+	for _, use := range f.Use {
+		dir := use.Path
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(filepath.Dir(goWork), dir)
+		}
+		path, err := modulePath(filepath.Join(dir, "go.mod"))
+		if err != nil {
+			continue
+		}
+		targets = append(targets, Target{Dir: dir, Path: path, GoWork: goWork})
+	}
+	return targets, nil
+}
+
+func modulePath(goMod string) (string, error) {
+	body, err := os.ReadFile(goMod)
+	if err != nil {
+		return "", err
+	}
+	path := modfile.ModulePath(body)
+	if path == "" {
+		return "", fmt.Errorf("%s has no module line", goMod)
+	}
+	return path, nil
+}
+
+// findGoWork mirrors how the go command picks a workspace: $GOWORK if set ("off" disables it),
+// otherwise the nearest go.work above dir.
+func findGoWork(dir string) string {
+	switch env := os.Getenv("GOWORK"); env {
+	case "off":
+		return ""
+	case "":
+		goWork, _ := findUp(dir, "go.work")
+		return goWork
+	default:
+		return env
+	}
+}
+
+func findUp(dir string, name string) (string, bool) {
+	for {
+		candidate := filepath.Join(dir, name)
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			return candidate, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// Requirements maps every module in a go.mod's require block to its version.
+type Requirements map[string]string
+
+// Required reads the requirements of the module in or above dir.
+func Required(dir string) (Requirements, error) {
+	mod, err := FindModule(dir)
+	if err != nil {
+		return nil, err
+	}
+	body, err := os.ReadFile(filepath.Join(mod.Dir, "go.mod"))
+	if err != nil {
+		return nil, err
+	}
+	f, err := modfile.ParseLax("go.mod", body, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	reqs := make(Requirements, len(f.Require))
+	for _, r := range f.Require {
+		reqs[r.Mod.Path] = r.Mod.Version
+	}
+	return reqs, nil
+}
+
+// Version looks up the required version of the module an import path belongs to. A path without a major
+// version suffix also matches its v2+ modules, as catalog links point at the repository rather than a major
+// version, so github.com/jackc/pgx and github.com/jackc/pgx/pgxpool find github.com/jackc/pgx/v5.
+// The longest, and so newest, match wins.
+func (r Requirements) Version(path string) (string, bool) {
+	required := ""
+	for mod := range r {
+		matches := path == mod || strings.HasPrefix(path, mod+"/")
+		if prefix, major, ok := module.SplitPathVersion(mod); ok && strings.HasPrefix(major, "/v") && inRepository(path, prefix) {
+			matches = true
+		}
+		if matches && len(mod) > len(required) {
+			required = mod
+		}
+	}
+	if required == "" {
+		return "", false
+	}
+	return r[required], true
+}
+
+// inRepository reports whether path is prefix or a package under it, without a major version of its own.
+func inRepository(path string, prefix string) bool {
+	if path == prefix {
+		return true
+	}
+	rest, ok := strings.CutPrefix(path, prefix+"/")
+	if !ok {
+		return false
+	}
+	first, _, _ := strings.Cut(rest, "/")
+	_, major, ok := module.SplitPathVersion(prefix + "/" + first)
+	return !ok || major == ""
+}
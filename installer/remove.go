@@ -0,0 +1,171 @@
+package installer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+)
+
+var (
+	// ErrNotRequired means the module being removed is not in go.mod to begin with.
+	ErrNotRequired = errors.New("not required by this module")
+	// ErrStillImported means source files import the module being removed, so removing it would break the build.
+	ErrStillImported = errors.New("still imported")
+)
+
+// Remove drops the module a package belongs to from go.mod, the equivalent of
+//
+//	go get path@none
+//	go mod tidy
+//
+// If source files still import it nothing is run and ErrStillImported is returned, with the files
+// listed in Result.StillImported. Request.Force removes it anyway, skipping go mod tidy since that
+// would only add it straight back.
+func (g *GoInstaller) Remove(ctx context.Context, req Request) (*Result, error) {
+	goBin, err := g.goBin()
+	if err != nil {
+		return nil, err
+	}
+
+	mod, err := FindModule(req.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	before, err := takeSnapshot(mod.Dir)
+	if err != nil {
+		return nil, err
+	}
+	required, importers, err := moduleImporters(mod.Dir, before.goMod, req.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{
+		Module:        required,
+		StillImported: importers,
+		snapshot:      before,
+	}
+	if len(importers) > 0 && !req.Force {
+		return result, fmt.Errorf("%w: %s by %s", ErrStillImported, required, strings.Join(importers, ", "))
+	}
+
+	steps := [][]string{{"get", required + "@none"}}
+	if len(importers) == 0 {
+		steps = append(steps, []string{"mod", "tidy"})
+	}
+	for _, args := range steps {
+		stdout, stderr, err := g.Runner.Run(ctx, mod.Dir, goBin, args...)
+		result.Stdout += string(stdout)
+		result.Stderr += string(stderr)
+		if err != nil {
+			return result, fmt.Errorf("go %s: %w\n%s", strings.Join(args, " "), err, strings.TrimSpace(string(stderr)))
+		}
+	}
+
+	after, _ := os.ReadFile(filepath.Join(mod.Dir, "go.mod"))
+	result.Requires = requireChanges(before.goMod, after)
+	return result, nil
+}
+
+// Importers finds the module in dir's go.mod that path belongs to, and the files still importing a package from it.
+// It is what Remove checks before removing anything, so callers can ask for confirmation first.
+func Importers(dir string, path string) (string, []string, error) {
+	mod, err := FindModule(dir)
+	if err != nil {
+		return "", nil, err
+	}
+	goMod, err := os.ReadFile(filepath.Join(mod.Dir, "go.mod"))
+	if err != nil {
+		return "", nil, err
+	}
+	return moduleImporters(mod.Dir, goMod, path)
+}
+
+func moduleImporters(dir string, goMod []byte, path string) (string, []string, error) {
+	required := requiredModule(goMod, path)
+	if required == "" {
+		return "", nil, fmt.Errorf("%w: %s", ErrNotRequired, path)
+	}
+	files, err := importersOf(dir, goMod, required)
+	if err != nil {
+		return "", nil, err
+	}
+	return required, files, nil
+}
+
+// requiredModule finds the longest module in go.mod that path is, or is inside of.
+func requiredModule(goMod []byte, path string) string {
+	f, err := modfile.ParseLax("go.mod", goMod, nil)
+	if err != nil {
+		return ""
+	}
+	return longestModule(f.Require, path)
+}
+
+func longestModule(required []*modfile.Require, path string) string {
+	module := ""
+	for _, r := range required {
+		if (path == r.Mod.Path || strings.HasPrefix(path, r.Mod.Path+"/")) && len(r.Mod.Path) > len(module) {
+			module = r.Mod.Path
+		}
+	}
+	return module
+}
+
+// importersOf lists the .go files in the module at dir (relative to it) that import a package from mod.
+// Imports are matched to the longest module go.mod requires, the way the go command does, so a nested
+// module like example.com/a/v2 or example.com/a/service doesn't count as importing example.com/a.
+// Nested modules, vendor and testdata are not part of the module so they are skipped.
+func importersOf(dir string, goMod []byte, mod string) ([]string, error) {
+	modFile, err := modfile.ParseLax("go.mod", goMod, nil)
+	if err != nil {
+		return nil, err
+	}
+	importers := []string{}
+	fset := token.NewFileSet()
+
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			name := d.Name()
+			if path != dir && (name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")) {
+				return filepath.SkipDir
+			}
+			if _, err := os.Stat(filepath.Join(path, "go.mod")); path != dir && err == nil {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".go") {
+			return nil
+		}
+
+		f, err := parser.ParseFile(fset, path, nil, parser.ImportsOnly)
+		if err != nil {
+			// Broken files are the user's business, not a reason to fail
+			return nil
+		}
+		for _, imp := range f.Imports {
+			p, _ := strconv.Unquote(imp.Path.Value)
+			if longestModule(modFile.Require, p) == mod {
+				rel, _ := filepath.Rel(dir, path)
+				importers = append(importers, rel)
+				break
+			}
+		}
+		return nil
+	})
+	return importers, err
+}
@@ -0,0 +1,231 @@
+package installer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+// ErrNoVersions means the proxy has no tagged versions for the package or any module containing it.
+var ErrNoVersions = errors.New("no versions found")
+
+// ErrNoProxy means $GOPROXY or $GONOPROXY/$GOPRIVATE say not to use a proxy for the module, so there is
+// nothing to list versions from. The go command can still fetch it directly.
+var ErrNoProxy = errors.New("no module proxy")
+
+var errNotFound = errors.New("not found")
+
+// Version is a published version of a module.
+type Version struct {
+	Version    string
+	Prerelease bool   // e.g. v2.0.0-rc.1
+	Retracted  bool   // The module author asked people not to use it
+	Rationale  string // Why it was retracted, if they said
+}
+
+// ProxyClient lists module versions over the GOPROXY protocol (https://go.dev/ref/mod#goproxy-protocol).
+// A file:// proxy works too, which is just a directory laid out the same way.
+type ProxyClient struct {
+	Proxy  string       // Base url of the proxy, in place of $GOPROXY
+	Client *http.Client // Defaults to http.DefaultClient
+}
+
+// Versions finds the module path belongs to and lists its versions, newest first.
+// The module is found by asking the proxy about path and then each parent of it in turn.
+func (c *ProxyClient) Versions(path string) (string, []Version, error) {
+	for mod := path; strings.Contains(mod, "/"); mod = mod[:strings.LastIndex(mod, "/")] {
+		list, err := c.list(mod)
+		if errors.Is(err, errNotFound) || (err == nil && len(list) == 0) {
+			continue
+		}
+		if err != nil {
+			return "", nil, err
+		}
+
+		versions := make([]Version, 0, len(list))
+		for _, v := range list {
+			versions = append(versions, Version{
+				Version:    v,
+				Prerelease: semver.Prerelease(v) != "",
+			})
+		}
+		sort.Slice(versions, func(i, j int) bool {
+			return semver.Compare(versions[i].Version, versions[j].Version) > 0
+		})
+
+		c.markRetracted(mod, versions)
+		return mod, versions, nil
+	}
+
+	return "", nil, fmt.Errorf("%w: %s", ErrNoVersions, path)
+}
+
+func (c *ProxyClient) list(mod string) ([]string, error) {
+	body, err := c.get(mod, "list")
+	if err != nil {
+		return nil, err
+	}
+
+	list := []string{}
+	for _, line := range strings.Split(string(body), "\n") {
+		// Lines can carry a timestamp after the version
+		fields := strings.Fields(line)
+		if len(fields) > 0 && semver.IsValid(fields[0]) {
+			list = append(list, fields[0])
+		}
+	}
+	return list, nil
+}
+
+// markRetracted reads the retract directives from the go.mod of the latest release (like the go command
+// does), that is where module authors put them. Failing to read it just means nothing gets marked.
+func (c *ProxyClient) markRetracted(mod string, versions []Version) {
+	if len(versions) == 0 {
+		return
+	}
+	latest := versions[0].Version
+	for _, v := range versions {
+		if !v.Prerelease {
+			latest = v.Version
+			break
+		}
+	}
+
+	body, err := c.get(mod, latest+".mod")
+	if err != nil {
+		return
+	}
+	f, err := modfile.ParseLax("go.mod", body, nil)
+	if err != nil {
+		return
+	}
+
+	for _, r := range f.Retract {
+		for i, v := range versions {
+			if semver.Compare(v.Version, r.Low) >= 0 && semver.Compare(v.Version, r.High) <= 0 {
+				versions[i].Retracted = true
+				versions[i].Rationale = r.Rationale
+			}
+		}
+	}
+}
+
+// get fetches <proxy>/<module>/@v/<file>.
+func (c *ProxyClient) get(mod string, file string) ([]byte, error) {
+	escaped, err := module.EscapePath(mod)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errNotFound, err)
+	}
+	proxy, err := c.proxy(mod)
+	if err != nil {
+		return nil, err
+	}
+	proxy = strings.TrimSuffix(proxy, "/")
+
+	if strings.HasPrefix(proxy, "file://") {
+		u, err := url.Parse(proxy)
+		if err != nil {
+			return nil, err
+		}
+		body, err := os.ReadFile(filepath.Join(filepath.FromSlash(u.Path), filepath.FromSlash(escaped), "@v", file))
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, errNotFound
+		}
+		return body, err
+	}
+
+	client := c.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Get(proxy + "/" + escaped + "/@v/" + file)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// The proxy protocol uses 404 and 410 for "no such module"
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone {
+		return nil, errNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %s", proxy, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// NewestMajor finds the newest major version of the module at path, for repositories that moved on to
+// /vN module paths (https://go.dev/ref/mod#major-version-suffixes), e.g. github.com/jackc/pgx ->
+// github.com/jackc/pgx/v5. Without any newer major it returns path itself.
+//
+// Majors are tried upwards from the one after the newest +incompatible release of path, until the
+// proxy has no module for one.
+func (c *ProxyClient) NewestMajor(path string) (string, error) {
+	prefix, _, ok := module.SplitPathVersion(path)
+	if !ok || prefix != path {
+		return path, nil
+	}
+
+	list, err := c.list(path)
+	if err != nil && !errors.Is(err, errNotFound) {
+		return path, err
+	}
+	major := 2
+	for _, v := range list {
+		if n, err := strconv.Atoi(strings.TrimPrefix(semver.Major(v), "v")); err == nil && n+1 > major {
+			major = n + 1
+		}
+	}
+
+	newest := path
+	for ; ; major++ {
+		next := fmt.Sprintf("%s/v%d", path, major)
+		list, err := c.list(next)
+		if errors.Is(err, errNotFound) || (err == nil && len(list) == 0) {
+			return newest, nil
+		}
+		if err != nil {
+			return path, err
+		}
+		newest = next
+	}
+}
+
+// defaultProxy is the proxy the go command uses when $GOPROXY is not set.
+const defaultProxy = "https://proxy.golang.org"
+
+// proxy picks the proxy to ask about mod the way the go command would: the first entry in $GOPROXY,
+// unless that is "direct" or "off", or mod matches $GONOPROXY (which defaults to $GOPRIVATE).
+func (c *ProxyClient) proxy(mod string) (string, error) {
+	noProxy := os.Getenv("GONOPROXY")
+	if noProxy == "" {
+		noProxy = os.Getenv("GOPRIVATE")
+	}
+	if module.MatchPrefixPatterns(noProxy, mod) {
+		return "", fmt.Errorf("%w: %s is private, $GONOPROXY or $GOPRIVATE", ErrNoProxy, mod)
+	}
+
+	if c.Proxy != "" {
+		return c.Proxy, nil
+	}
+	env := os.Getenv("GOPROXY")
+	proxies := strings.FieldsFunc(env, func(r rune) bool { return r == ',' || r == '|' })
+	if len(proxies) == 0 {
+		return defaultProxy, nil
+	}
+	if proxies[0] == "direct" || proxies[0] == "off" {
+		return "", fmt.Errorf("%w: GOPROXY=%s", ErrNoProxy, env)
+	}
+	return proxies[0], nil
+}
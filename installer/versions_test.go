@@ -0,0 +1,142 @@
+package installer
+
+import (
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// fileProxy lays out a file:// proxy in a temporary directory, files maps module path and file name to contents.
+func fileProxy(t *testing.T, files map[[2]string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for key, body := range files {
+		path := filepath.Join(dir, filepath.FromSlash(key[0]), "@v", key[1])
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(dir)}).String()
+}
+
+func TestVersionsFileProxy(t *testing.T) {
+	t.Setenv("GONOPROXY", "")
+	t.Setenv("GOPRIVATE", "")
+	proxy := fileProxy(t, map[[2]string]string{
+		{"example.com/mod", "list"}: "v1.0.0\nv1.2.0 2026-01-02T15:04:05Z\nv1.1.0\nv2.0.0-rc.1\nnot-a-version\n",
+		// Retractions are read from the latest release, not the pre-release
+		{"example.com/mod", "v1.2.0.mod"}:      "module example.com/mod\n\nretract v1.1.0 // Broke the build\nretract [v1.0.0, v1.0.5]\n",
+		{"example.com/mod", "v2.0.0-rc.1.mod"}: "module example.com/mod\n\nretract v1.2.0\n",
+	})
+	c := &ProxyClient{Proxy: proxy}
+
+	mod, got, err := c.Versions("example.com/mod/sub/pkg")
+	if err != nil {
+		t.Fatalf("Versions: %v", err)
+	}
+	if mod != "example.com/mod" {
+		t.Errorf("module = %q, want the parent the proxy knows about", mod)
+	}
+	want := []Version{
+		{Version: "v2.0.0-rc.1", Prerelease: true},
+		{Version: "v1.2.0"},
+		{Version: "v1.1.0", Retracted: true, Rationale: "Broke the build"},
+		{Version: "v1.0.0", Retracted: true},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Versions =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestVersionsNotFound(t *testing.T) {
+	t.Setenv("GONOPROXY", "")
+	t.Setenv("GOPRIVATE", "")
+	c := &ProxyClient{Proxy: fileProxy(t, map[[2]string]string{
+		{"example.com/empty", "list"}: "",
+	})}
+
+	for _, path := range []string{"example.com/missing/pkg", "example.com/empty"} {
+		if _, _, err := c.Versions(path); !errors.Is(err, ErrNoVersions) {
+			t.Errorf("Versions(%q) error = %v, want ErrNoVersions", path, err)
+		}
+	}
+}
+
+func TestProxy(t *testing.T) {
+	tests := []struct {
+		goproxy, gonoproxy, goprivate string
+		mod                           string
+		want                          string
+		err                           error
+	}{
+		{"", "", "", "example.com/mod", "https://proxy.golang.org", nil},
+		{"https://goproxy.example.com,direct", "", "", "example.com/mod", "https://goproxy.example.com", nil},
+		{"https://a.example.com|https://b.example.com", "", "", "example.com/mod", "https://a.example.com", nil},
+		{"off", "", "", "example.com/mod", "", ErrNoProxy},
+		{"direct", "", "", "example.com/mod", "", ErrNoProxy},
+		{"direct,https://proxy.golang.org", "", "", "example.com/mod", "", ErrNoProxy},
+		{"", "", "example.com/private,*.corp.example", "example.com/private/mod", "", ErrNoProxy},
+		{"", "", "example.com/private,*.corp.example", "git.corp.example/team/mod", "", ErrNoProxy},
+		{"", "", "example.com/private", "example.com/public", "https://proxy.golang.org", nil},
+		// GONOPROXY takes the place of GOPRIVATE when both are set
+		{"", "example.com/other", "example.com/private", "example.com/private/mod", "https://proxy.golang.org", nil},
+		{"", "example.com/other", "example.com/private", "example.com/other/mod", "", ErrNoProxy},
+	}
+	for _, tt := range tests {
+		t.Setenv("GOPROXY", tt.goproxy)
+		t.Setenv("GONOPROXY", tt.gonoproxy)
+		t.Setenv("GOPRIVATE", tt.goprivate)
+
+		got, err := (&ProxyClient{}).proxy(tt.mod)
+		if got != tt.want || !errors.Is(err, tt.err) || (tt.err == nil && err != nil) {
+			t.Errorf("GOPROXY=%q GONOPROXY=%q GOPRIVATE=%q proxy(%q) = %q, %v, want %q, %v",
+				tt.goproxy, tt.gonoproxy, tt.goprivate, tt.mod, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestVersionsNoProxy(t *testing.T) {
+	t.Setenv("GOPROXY", "off")
+	if _, _, err := (&ProxyClient{}).Versions("example.com/mod"); !errors.Is(err, ErrNoProxy) {
+		t.Errorf("got %v, want ErrNoProxy rather than asking proxy.golang.org", err)
+	}
+}
+
+func TestNewestMajor(t *testing.T) {
+	t.Setenv("GONOPROXY", "")
+	t.Setenv("GOPRIVATE", "")
+	c := &ProxyClient{Proxy: fileProxy(t, map[[2]string]string{
+		// v2 and v3 were tagged before the repository became a module, v4 and v5 are modules of their own
+		{"example.com/pgx", "list"}:       "v2.0.0+incompatible\nv3.6.2+incompatible\n",
+		{"example.com/pgx/v4", "list"}:    "v4.18.0\n",
+		{"example.com/pgx/v5", "list"}:    "v5.5.0\n",
+		{"example.com/cobra", "list"}:     "v1.8.0\n",
+		{"example.com/v2only", "list"}:    "v1.0.0\n",
+		{"example.com/v2only/v2", "list"}: "v2.1.0\n",
+		{"example.com/empty/v2", "list"}:  "",
+	})}
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"example.com/pgx", "example.com/pgx/v5"},
+		{"example.com/cobra", "example.com/cobra"},
+		{"example.com/v2only", "example.com/v2only/v2"},
+		{"example.com/empty", "example.com/empty"},
+		{"example.com/missing", "example.com/missing"},
+		{"example.com/pgx/v4", "example.com/pgx/v4"}, // Already a major version, left alone
+	}
+	for _, tt := range tests {
+		got, err := c.NewestMajor(tt.path)
+		if err != nil || got != tt.want {
+			t.Errorf("NewestMajor(%q) = %q, %v, want %q", tt.path, got, err, tt.want)
+		}
+	}
+}
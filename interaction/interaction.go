@@ -1,29 +1,46 @@
 package interaction
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
 
 	"github.com/buger/goterm"
-	"github.com/inancgumus/screen"
-	"github.com/pkg/term"
 )
 
+// Interaction is the one event loop behind every menu. What a prompt looks like and which keys it
+// takes is down to the widgets it is built from, see CreatePrompt and CreateSearchPrompt.
 type Interaction struct {
-	Prompts           map[int]*Prompt
-	CurrentIdx        int
-	NextInsertIdx     int
-	CursorIdx         int
-	LinesOnLastRender int
+	Selection
+	Prompts       map[int]*Prompt
+	CurrentIdx    int
+	NextInsertIdx int
+	Message       string // What the last callback or batch action reported, shown by the StatusBar
+	MessageErr    error
+	Terminal      Terminal // Where keys come from and frames go, the process's own terminal by default
+	Keymap        Keymap
+
+	done bool
+	err  error // Why the interaction ended, if it wasn't simply exited
+
+	mu       sync.Mutex // Guards drawing, a callback can draw progress from the goroutine it runs on
+	released bool       // Set once Open has handed the terminal back, nothing is drawn after that
 }
 
 type Prompt struct {
-	Description string
+	Description string // Key hints, when empty the focused widget's hints are shown instead
 	Title       string
-	Options     [][]*Option
 	Idx         int
-	PageIdx     int
 	ParentIdx   int
-	IsPaginated bool
+	Widgets     []Widget        // Drawn top to bottom
+	Focus       int             // Index in Widgets of the widget that gets key presses first
+	List        *List           // The prompt's options, AddOption adds to it
+	OnOpen      func(p *Prompt) // Called every time the prompt is navigated to, e.g. to fill in options lazily
 }
 
 type Option struct {
@@ -32,67 +49,95 @@ type Option struct {
 	Title       string
 	Description string
 	PromptIdx   int
+	Highlights  []int // Rune offsets in Title to highlight, e.g. the characters a search matched
+	Selectable  bool  // Can be picked with [space] for a batch action
+	Selected    bool
 }
 
 func NewInteraction() *Interaction {
 	return &Interaction{
-		Prompts:           make(map[int]*Prompt, 0),
-		CurrentIdx:        0,
-		NextInsertIdx:     0,
-		CursorIdx:         0,
-		LinesOnLastRender: 0,
+		Prompts:       make(map[int]*Prompt, 0),
+		CurrentIdx:    0,
+		NextInsertIdx: 0,
+		Terminal:      NewTTY(),
+		Keymap:        DefaultKeymap(),
 	}
 }
 
+// CreatePrompt adds a plain menu: a list of options with a status bar under it.
 func (i *Interaction) CreatePrompt(title string, description string, isPaginated bool) *Prompt {
+	p := i.newPrompt(title, description)
+	p.List = NewList(isPaginated)
+	p.Widgets = []Widget{p.List, &StatusBar{}}
+	return p
+}
+
+func (i *Interaction) newPrompt(title string, description string) *Prompt {
 	p := &Prompt{
 		Title:       title,
 		Description: description,
-		Options:     make([][]*Option, 0),
 		Idx:         i.NextInsertIdx,
-		PageIdx:     0,
-		IsPaginated: isPaginated,
 	}
-
-	firstPage := []*Option{}
-	p.Options = append(p.Options, firstPage)
 	i.Prompts[p.Idx] = p
 	i.NextInsertIdx += 1
-
 	return p
 }
 
 func (p *Prompt) AddOption(title string, description string, packet any) *Option {
-	o := &Option{
-		Title:       title,
-		Description: description,
-		Packet:      packet,
-	}
+	return p.List.AddOption(title, description, packet)
+}
 
-	// Skip paginating
-	if !p.IsPaginated {
-		p.Options[0] = append(p.Options[0], o)
-		return o
-	}
+// ClearOptions removes every option, for prompts that are rebuilt each time they are opened.
+func (p *Prompt) ClearOptions() {
+	p.List.Clear()
+}
 
-	// Get idx of the last page
-	lastPage := len(p.Options) - 1
+// Len is how many options the prompt has.
+func (p *Prompt) Len() int {
+	return len(p.List.Options)
+}
 
-	// Fill to 10
-	if len(p.Options[lastPage]) < 10 {
-		p.Options[lastPage] = append(p.Options[lastPage], o)
-		return o
+func (p *Prompt) AttachParent(parentIdx int) {
+	p.ParentIdx = parentIdx
+}
+
+// Focused is the widget key presses go to first.
+func (p *Prompt) Focused() Widget {
+	if p.Focus < 0 || p.Focus >= len(p.Widgets) {
+		return nil
 	}
+	return p.Widgets[p.Focus]
+}
 
-	// Create new page if already at 10
-	newPage := []*Option{o}
-	p.Options = append(p.Options, newPage)
+// FocusOn moves the focus to w, if it is one of the prompt's widgets and can take it.
+func (p *Prompt) FocusOn(w Widget) {
+	for j, v := range p.Widgets {
+		if v == w && v.Focusable() {
+			p.Focus = j
+			return
+		}
+	}
+}
 
-	return o
+// cycleFocus moves the focus to the next widget that can take it.
+func (p *Prompt) cycleFocus() {
+	for step := 1; step <= len(p.Widgets); step++ {
+		j := (p.Focus + step) % len(p.Widgets)
+		if p.Widgets[j].Focusable() {
+			p.Focus = j
+			return
+		}
+	}
 }
 
-func (p *Prompt) AttachParent(parentIdx int) {
-	p.ParentIdx = parentIdx
+// input is the prompt's first text input, if it has one.
+func (p *Prompt) input() *TextInput {
+	for _, w := range p.Widgets {
+		if t, ok := w.(*TextInput); ok {
+			return t
+		}
+	}
+	return nil
 }
 
 func (o *Option) AttachPrompt(promptIdx int) {
@@ -107,150 +152,290 @@ func (i *Interaction) getCurrentPrompt() *Prompt {
 	return i.Prompts[i.CurrentIdx]
 }
 
-func (i *Interaction) Open() *Option {
-	// Hide cursor and return it on close
+// ErrInterrupted means the interaction was ended by SIGINT, SIGTERM or ctrl+c rather than exited.
+var ErrInterrupted = errors.New("interrupted")
+
+// InterruptedError is what Open returns when it is interrupted, saying which signal did it. It matches ErrInterrupted.
+type InterruptedError struct {
+	Signal os.Signal // SIGINT for ctrl+c, the same as a terminal not in raw mode would send
+	Key    string    // The key pressed, empty for a real signal
+}
+
+func (e *InterruptedError) Error() string {
+	if e.Key != "" {
+		return fmt.Sprintf("%s: %s", ErrInterrupted, e.Key)
+	}
+	return fmt.Sprintf("%s: %s", ErrInterrupted, e.Signal)
+}
+
+func (e *InterruptedError) Unwrap() error {
+	return ErrInterrupted
+}
+
+// Open runs the interaction until it is exited. A fake terminal running out of keys ends it without an error.
+// The terminal is acquired once for the whole interaction and handed back however it ends.
+//
+// Keys are handled on a goroutine of their own, one at a time, so SIGINT and SIGTERM still end the
+// interaction while a callback takes its time. The callback is left to finish in the background, but
+// nothing it draws reaches the terminal after Open returns.
+func (i *Interaction) Open() error {
+	i.mu.Lock()
+	i.done, i.err, i.released = false, nil, false
+	i.mu.Unlock()
+
+	if err := i.Terminal.Acquire(); err != nil {
+		return fmt.Errorf("taking over the terminal: %w", err)
+	}
+	// Deferred so a panic in a callback still leaves the terminal usable, with its cursor showing
 	defer func() {
-		fmt.Printf("\033[?25h")
+		i.mu.Lock()
+		defer i.mu.Unlock()
+		i.released = true
+		fmt.Fprint(i.Terminal, showCursor)
+		i.Terminal.Release()
 	}()
-	fmt.Printf("\033[?25l")
+	i.draw(hideCursor)
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM, syscall.SIGWINCH)
+	defer signal.Stop(signals)
+
+	keys, errs, stop := i.readKeys()
+	defer close(stop)
 
 	// Render initial state
+	i.HardFlushScreen()
 	i.Render()
+
+	// handled gets what the key's handler panicked with, nil when it didn't. Buffered so a handler
+	// still running when Open returns can finish.
+	handled := make(chan any, 1)
+	busy, resized := false, false
 	for {
-		p := i.getCurrentPrompt()
-		pLen := len(p.Options[p.PageIdx])
-		key := userInput()
-
-		switch key {
-		case escape:
-			return &Option{}
-		case n:
-			if p.PageIdx+1 < len(p.Options) {
-				p.PageIdx += 1
-				i.CursorIdx = 0
-				i.Render()
+		// While a key is being handled the interaction's state belongs to its goroutine, so no more
+		// keys are taken and resizes wait. A fake terminal's last error waits too, its keys aren't done.
+		nextKey, nextErr := keys, errs
+		if busy {
+			nextKey, nextErr = nil, nil
+		}
+
+		select {
+		case key := <-nextKey:
+			busy = true
+			go func() {
+				defer func() { handled <- recover() }()
+				i.handleKey(key)
+			}()
+		case p := <-handled:
+			busy = false
+			if p != nil {
+				panic(p)
 			}
-		case b:
-			if p.PageIdx-1 >= 0 {
-				p.PageIdx -= 1
-				i.CursorIdx = 0
-				i.Render()
+			if i.done {
+				return i.err
 			}
-		case up:
-			if i.CursorIdx-1 >= 0 {
-				i.CursorIdx -= 1
+			if resized {
+				resized = false
 				i.Render()
 			}
-		case down:
-			if i.CursorIdx+1 < pLen {
-				i.CursorIdx += 1
-				i.Render()
+		case err := <-nextErr:
+			if errors.Is(err, ErrNoMoreKeys) {
+				return nil
 			}
-		case enter:
-			selectedOption := p.Options[p.PageIdx][i.CurrentIdx]
-
-			// If the option has children render that
-			if selectedOption.PromptIdx > 0 {
-				i.RenderNewPrompt(selectedOption.PromptIdx)
+			return fmt.Errorf("reading from the terminal: %w", err)
+		case sig := <-signals:
+			if sig != syscall.SIGWINCH {
+				return &InterruptedError{Signal: sig}
 			}
+			if busy {
+				resized = true
+				continue
+			}
+			i.Render()
+		}
+	}
+}
 
-			// Otherwise handle the option
-			if selectedOption.Callback != nil {
-				message, err := selectedOption.Callback()
-				fmt.Println("\n\n\n", message, "\n\n\n", err)
+// readKeys reads key presses on a goroutine of their own, so Open can wait on signals at the same time.
+// After stop is closed the goroutine is left blocked in a read until the next key press or the process exits.
+func (i *Interaction) readKeys() (<-chan Key, <-chan error, chan<- struct{}) {
+	keys := make(chan Key)
+	errs := make(chan error, 1)
+	stop := make(chan struct{})
+	go func() {
+		for {
+			key, err := i.Terminal.ReadKey()
+			if err != nil {
+				errs <- err
+				return
 			}
-		case u: // naviagte up
-			if p.ParentIdx >= 0 {
-				i.RenderNewPrompt(p.ParentIdx)
+			select {
+			case keys <- key:
+			case <-stop:
+				return
 			}
 		}
+	}()
+	return keys, errs, stop
+}
+
+// handleKey gives a key press to the focused widget, and if it had no use for it, tries the keys
+// every prompt shares.
+func (i *Interaction) handleKey(key Key) {
+	p := i.getCurrentPrompt()
+	i.Message, i.MessageErr = "", nil
+
+	if w := p.Focused(); w != nil && w.HandleKey(i, key) {
+		if !i.done {
+			i.Render()
+		}
+		return
 	}
+
+	switch i.Keymap.Action(key) {
+	case ActionExit:
+		i.done = true
+		return
+	case ActionInterrupt:
+		i.done = true
+		i.err = &InterruptedError{Signal: syscall.SIGINT, Key: key.String()}
+		return
+	case ActionBack: // naviagte up
+		if p.ParentIdx == p.Idx {
+			return
+		}
+		i.Navigate(p.ParentIdx)
+	case ActionFocus:
+		p.cycleFocus()
+	case ActionSearch:
+		if t := p.input(); t != nil {
+			p.FocusOn(t)
+		}
+	case ActionResults:
+		p.FocusOn(p.List)
+	case ActionBatch:
+		i.Message, i.MessageErr = i.RunBatch()
+	default:
+		return
+	}
+	i.Render()
 }
 
-func (i *Interaction) RenderNewPrompt(newIdx int) {
+// Select does what picking an option means: open the prompt it leads to, or run its callback.
+func (i *Interaction) Select(o *Option) {
+	if o == nil {
+		return
+	}
+
+	// If the option has children render that
+	if o.PromptIdx > 0 {
+		i.Navigate(o.PromptIdx)
+	}
+
+	// Otherwise handle the option
+	if o.Callback != nil {
+		i.Message, i.MessageErr = o.Callback()
+	}
+}
+
+// RunBatch is Selection.RunBatch, saying which key selects things when nothing is selected.
+func (i *Interaction) RunBatch() (string, error) {
+	if i.BatchAction != nil && len(i.Selected) == 0 {
+		if keys := i.Keymap.Keys(ActionToggle); len(keys) > 0 {
+			return "Nothing selected, press [" + keys[0].String() + "] on a package to select it.", nil
+		}
+	}
+	return i.Selection.RunBatch()
+}
+
+// Status shows a message in the status bar straight away, for callbacks that take a while to report progress.
+func (i *Interaction) Status(message string) {
+	i.Message, i.MessageErr = message, nil
+	i.Render()
+}
+
+// Navigate makes another prompt the current one, starting it from the top.
+func (i *Interaction) Navigate(newIdx int) {
 	i.CurrentIdx = newIdx
-	i.CursorIdx = 0
-	i.Prompts[i.CurrentIdx].PageIdx = 0
+	p := i.Prompts[i.CurrentIdx]
+	if p.List != nil {
+		p.List.Reset()
+	}
+	if p.OnOpen != nil {
+		p.OnOpen(p)
+	}
+}
+
+func (i *Interaction) RenderNewPrompt(newIdx int) {
+	i.Navigate(newIdx)
 	i.Render()
 }
 
-// This makes our UI really easy to work with but im not a huge fan of clearing any past context...
+// HardFlushScreen wipes everything, frames after it are drawn over each other.
 func (i *Interaction) HardFlushScreen() {
-	screen.Clear()
-	screen.MoveTopLeft()
+	i.draw(clearScreen)
+}
+
+// draw writes to the terminal, unless Open has already handed it back.
+func (i *Interaction) draw(s string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if i.released {
+		return
+	}
+	fmt.Fprint(i.Terminal, s)
 }
 
-// Render is called on any user input action and by default repaints the current Prompt
+// Render is called on any user input action and by default repaints the current Prompt.
+// Everything is laid out to the size of the terminal: the list gets whatever rows the title and the
+// prompt's other widgets leave over, so it is asked again on every render, a resize included.
 func (i *Interaction) Render() {
-	// For now we are just going to hard clear. can come back to this later.
-	i.HardFlushScreen()
 	p := i.getCurrentPrompt()
-	// Redraw
-	// This *MOSTLY* works but Im sure will need to be changed a bit.
-	if i.LinesOnLastRender > 1 {
-		fmt.Printf("\033[%dA", i.LinesOnLastRender)
+	width, height := i.Terminal.Size()
+
+	hints := p.Description
+	if w := p.Focused(); hints == "" && w != nil {
+		hints = w.Hints(i)
 	}
-	i.LinesOnLastRender = 0
 
-	// Draw prompts title
-	fmt.Printf("\r%s\n%s\n", goterm.Color(goterm.Bold(p.Title), goterm.CYAN), goterm.Color(p.Description, goterm.MAGENTA))
-	i.LinesOnLastRender += 2
+	// The frame is put together first and written in one go, so it never shows half drawn
+	var frame bytes.Buffer
 
-	optionsToRender := p.Options[p.PageIdx]
-	// Because we are repainting, we need to ensure it gets cleared fully.
-	linePadding := "                                                                               "
-	nl := "\n"
+	// Draw prompts title
+	fmt.Fprintln(&frame, truncate(goterm.Color(goterm.Bold(p.Title), goterm.CYAN), width))
+	for _, line := range wrap(hints, width) {
+		fmt.Fprintln(&frame, goterm.Color(line, goterm.MAGENTA))
+	}
 
-	for j, v := range optionsToRender {
-		if j == len(optionsToRender)-1 {
-			nl = ""
+	// Widgets other than the list are drawn first to see how much room they take
+	drawn := make([]string, len(p.Widgets))
+	used := countLines(frame.String())
+	for j, w := range p.Widgets {
+		if w == Widget(p.List) {
+			continue
 		}
-		switch j == i.CursorIdx {
-		case true:
-			fmt.Printf("\r%s%s%s%s",
-				goterm.Color(goterm.Bold(">  "), goterm.YELLOW),
-				goterm.Color(goterm.Bold(v.Title), goterm.YELLOW),
-				goterm.Bold(" ("+v.Description+") "+linePadding),
-				nl)
-		case false:
-			fmt.Printf("\r%s%s%s%s", "  ", v.Title, " ("+v.Description+") "+linePadding, "\n")
-		}
-		i.LinesOnLastRender += 1
+		var b bytes.Buffer
+		w.Render(&b, i, j == p.Focus, width)
+		drawn[j] = b.String()
+		used += countLines(drawn[j])
 	}
-}
-
-// Raw input keycodes
-var (
-	u         byte = 117
-	up        byte = 65
-	down      byte = 66
-	escape    byte = 27
-	enter     byte = 13
-	n         byte = 110
-	b         byte = 98
-	search    byte = 43
-	results   byte = 61
-	r         byte = 114
-	backspace byte = 127
-)
-
-func userInput() byte {
-	t, _ := term.Open("/dev/tty")
-	term.RawMode(t)
-
-	// Read in 3 bytes at a time
-	var action int
-	bytes := make([]byte, 3)
-	action, _ = t.Read(bytes)
 
-	t.Restore()
-	t.Close()
+	// The last row is left empty, a newline on it would scroll the frame up
+	for j, w := range p.Widgets {
+		if w != Widget(p.List) {
+			continue
+		}
+		p.List.Fit(height - used - 1)
+		var b bytes.Buffer
+		w.Render(&b, i, j == p.Focus, width)
+		drawn[j] = b.String()
+	}
 
-	// Arrow keys have a <esc>[ prefix so the 3rd byte is actually what we want
-	// Otherwise we just want the initial byte
-	if action == 3 {
-		return bytes[2]
-	} else {
-		return bytes[0]
+	for _, d := range drawn {
+		frame.WriteString(d)
 	}
+
+	// The terminal is in raw mode, so newlines need a carriage return to get back to the start of the line
+	lines := strings.ReplaceAll(frame.String(), "\n", clearLine+"\r\n")
+	i.draw(frameStart + lines + clearBelow)
 }
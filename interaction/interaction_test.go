@@ -0,0 +1,358 @@
+package interaction
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+var ansi = regexp.MustCompile("\033\\[[0-9;?]*[A-Za-z]")
+
+// plain strips colors out of a frame so it can be matched as text.
+func plain(frame string) string {
+	return ansi.ReplaceAllString(frame, "")
+}
+
+// unwrap joins a frame's lines back up, for matching text that was wrapped to the terminal's width.
+func unwrap(frame string) string {
+	return strings.Join(strings.Fields(frame), " ")
+}
+
+// open runs i on a fake terminal playing keys and returns the frames it drew, colors stripped.
+func open(t *testing.T, i *Interaction, keys ...Key) []string {
+	t.Helper()
+	term := NewFakeTerminal(keys...)
+	i.Terminal = term
+	if err := i.Open(); err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if term.Acquired {
+		t.Errorf("terminal still acquired after Open returned")
+	}
+	if !strings.HasSuffix(term.Output.String(), showCursor) {
+		t.Errorf("cursor not shown again after Open returned")
+	}
+
+	frames := term.Frames()
+	for j := range frames {
+		frames[j] = plain(frames[j])
+	}
+	return frames
+}
+
+var (
+	keyUp    = Key{Code: KeyUp}
+	keyDown  = Key{Code: KeyDown}
+	keyEnter = Key{Code: KeyEnter}
+	keyTab   = Key{Code: KeyTab}
+)
+
+func TestOpenNavigation(t *testing.T) {
+	i := NewInteraction()
+	home := i.CreatePrompt("Home", "", true)
+	home.AddOption("first", "the first option", nil)
+	child := i.CreatePrompt("Child prompt", "", false)
+	child.AttachParent(home.Idx)
+	child.AddOption("leaf", "an option in the child", nil)
+	home.AddOption("second", "leads to the child", nil).AttachPrompt(child.Idx)
+
+	frames := open(t, i, keyDown, keyEnter, Runes("u")[0])
+	if len(frames) != 4 {
+		t.Fatalf("got %d frames, want one to start and one per key", len(frames))
+	}
+
+	if !strings.Contains(frames[0], ">  first") {
+		t.Errorf("cursor should start on the first option:\n%s", frames[0])
+	}
+	if !strings.Contains(frames[1], ">  second") {
+		t.Errorf("down should move the cursor to the second option:\n%s", frames[1])
+	}
+	if !strings.Contains(frames[2], "Child prompt") || !strings.Contains(frames[2], "leaf") {
+		t.Errorf("enter should open the child prompt:\n%s", frames[2])
+	}
+	if !strings.Contains(frames[3], "Home") || !strings.Contains(frames[3], ">  first") {
+		t.Errorf("u should go back to the top of the parent:\n%s", frames[3])
+	}
+}
+
+func TestOpenPagination(t *testing.T) {
+	i := NewInteraction()
+	home := i.CreatePrompt("Home", "hints", true)
+	for j := 0; j < 12; j++ {
+		home.AddOption(fmt.Sprintf("option-%02d", j), "", nil)
+	}
+
+	term := NewFakeTerminal(Runes("n")[0], Runes("n")[0], keyUp, Runes("b")[0])
+	// Title, hints and the status bar's blank line leave 6 rows, 5 options and the page number
+	term.Height = 9
+	i.Terminal = term
+	if err := i.Open(); err != nil {
+		t.Fatal(err)
+	}
+	frames := term.Frames()
+	for j := range frames {
+		frames[j] = plain(frames[j])
+	}
+
+	tests := []struct {
+		frame    int
+		page     string
+		cursorOn string
+	}{
+		{0, "Page 1 of 3", "option-00"},
+		{1, "Page 2 of 3", "option-05"},
+		{2, "Page 3 of 3", "option-10"},
+		{3, "Page 2 of 3", "option-09"}, // Up off the top of a page goes to the end of the one before
+		{4, "Page 1 of 3", "option-00"},
+	}
+	for _, tt := range tests {
+		frame := frames[tt.frame]
+		if !strings.Contains(frame, tt.page) {
+			t.Errorf("frame %d: want %q:\n%s", tt.frame, tt.page, frame)
+		}
+		if !strings.Contains(frame, ">  "+tt.cursorOn) {
+			t.Errorf("frame %d: want the cursor on %s:\n%s", tt.frame, tt.cursorOn, frame)
+		}
+		if lines := strings.Count(frame, "\n"); lines >= term.Height {
+			t.Errorf("frame %d: %d lines does not fit a %d row terminal", tt.frame, lines, term.Height)
+		}
+	}
+}
+
+func TestOpenCallbacks(t *testing.T) {
+	i := NewInteraction()
+	home := i.CreatePrompt("Home", "", false)
+	var called []any
+	home.AddOption("works", "", nil).AddCallback(func(args ...any) (string, error) {
+		called = append(called, "works")
+		return "It worked.", nil
+	})
+	home.AddOption("fails", "", nil).AddCallback(func(args ...any) (string, error) {
+		called = append(called, "fails")
+		return "It did not work.", errors.New("something broke")
+	})
+
+	frames := open(t, i, keyEnter, keyDown, keyEnter, keyUp)
+	if len(called) != 2 {
+		t.Fatalf("callbacks called %v, want both once", called)
+	}
+	if !strings.Contains(frames[1], "It worked.") {
+		t.Errorf("callback message missing from the status bar:\n%s", frames[1])
+	}
+	if !strings.Contains(frames[3], "It did not work.") || !strings.Contains(frames[3], "something broke") {
+		t.Errorf("callback error missing from the status bar:\n%s", frames[3])
+	}
+	if strings.Contains(frames[4], "It did not work.") {
+		t.Errorf("status should be cleared by the next key press:\n%s", frames[4])
+	}
+}
+
+func TestOpenToggleAndBatch(t *testing.T) {
+	i := NewInteraction()
+	home := i.CreatePrompt("Home", "", false)
+	a := home.AddOption("a", "", nil)
+	a.Selectable = true
+	b := home.AddOption("b", "", nil)
+	b.Selectable = true
+	home.AddOption("not selectable", "", nil)
+
+	var batch []*Option
+	i.BatchAction = func(selected []*Option) (string, error) {
+		batch = selected
+		i.Status("working...")
+		return "Did the batch.", nil
+	}
+
+	space := Runes(" ")[0]
+	frames := open(t, i, keyDown, space, keyUp, space, keyDown, keyDown, space, Runes("i")[0])
+
+	if !strings.Contains(frames[2], "[x] b") || !strings.Contains(frames[2], "[ ] a") {
+		t.Errorf("space should select b only:\n%s", frames[2])
+	}
+	if strings.Contains(frames[7], "[ ] not selectable") || strings.Contains(frames[7], "[x] not selectable") {
+		t.Errorf("options that aren't selectable get no checkbox:\n%s", frames[7])
+	}
+	if len(batch) != 2 || batch[0] != b || batch[1] != a {
+		t.Errorf("batch got %v, want b then a, in the order they were picked", batch)
+	}
+	if !strings.Contains(frames[8], "working...") {
+		t.Errorf("progress from the batch action should be drawn:\n%s", frames[8])
+	}
+	last := frames[len(frames)-1]
+	if !strings.Contains(last, "Did the batch.") || !strings.Contains(last, "[ ] a") {
+		t.Errorf("batch should report and clear the selection:\n%s", last)
+	}
+}
+
+func TestOpenSearch(t *testing.T) {
+	i := NewInteraction()
+	home, search := i.CreateSearchPrompt("Search")
+	for _, name := range []string{"alpha", "nubby", "unbound"} {
+		search.AddToIndex(home.AddOption(name, "about "+name, nil))
+	}
+
+	// n, u and b are typed into the search bar rather than paging or going back
+	keys := append(Runes("nub"), keyTab)
+	frames := open(t, i, keys...)
+
+	last := frames[len(frames)-1]
+	if !strings.Contains(last, "Search >>nub") {
+		t.Errorf("typed text missing from the search bar:\n%s", last)
+	}
+	if !strings.Contains(last, ">  nubby") || strings.Contains(last, "alpha") {
+		t.Errorf("results should be filtered to nubby, with the focus on them:\n%s", last)
+	}
+	if !strings.Contains(last, "about nubby") {
+		t.Errorf("detail pane should describe the option under the cursor:\n%s", last)
+	}
+}
+
+func TestOpenWrapsToWidth(t *testing.T) {
+	i := NewInteraction()
+	home := i.CreatePrompt("Home", "", false)
+	home.AddOption("long", strings.Repeat("word ", 40), nil)
+	home.AddOption("日本語のパッケージ名", strings.Repeat("全角の説明文 ", 20), nil)
+
+	term := NewFakeTerminal()
+	term.Width = 30
+	i.Terminal = term
+	if err := i.Open(); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range strings.Split(plain(term.LastFrame()), "\n") {
+		if n := visibleWidth(line); n > term.Width {
+			t.Errorf("line is %d wide on a %d column terminal: %q", n, term.Width, line)
+		}
+	}
+}
+
+func TestOpenCtrlCInterrupts(t *testing.T) {
+	i := NewInteraction()
+	i.CreatePrompt("Home", "", false).AddOption("first", "", nil)
+	term := NewFakeTerminal(Key{Code: KeyCtrl, Rune: 'c'}, keyDown)
+	i.Terminal = term
+
+	err := i.Open()
+	var interrupted *InterruptedError
+	if !errors.Is(err, ErrInterrupted) || !errors.As(err, &interrupted) || interrupted.Signal != syscall.SIGINT {
+		t.Errorf("got %v, want ErrInterrupted by SIGINT", err)
+	}
+	if term.Acquired || len(term.Frames()) != 1 {
+		t.Errorf("ctrl+c should end the interaction straight away and hand the terminal back")
+	}
+}
+
+func TestOpenSignalDuringCallback(t *testing.T) {
+	i := NewInteraction()
+	home := i.CreatePrompt("Home", "", false)
+	finish := make(chan struct{})
+	defer close(finish)
+	home.AddOption("slow", "", nil).AddCallback(func(args ...any) (string, error) {
+		syscall.Kill(os.Getpid(), syscall.SIGTERM)
+		<-finish
+		i.Status("drawn after Open returned")
+		return "", nil
+	})
+	term := NewFakeTerminal(keyEnter)
+	i.Terminal = term
+
+	done := make(chan error)
+	go func() { done <- i.Open() }()
+	select {
+	case err := <-done:
+		var interrupted *InterruptedError
+		if !errors.Is(err, ErrInterrupted) || !errors.As(err, &interrupted) || interrupted.Signal != syscall.SIGTERM {
+			t.Errorf("got %v, want ErrInterrupted by SIGTERM", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("SIGTERM was not handled while a callback was running")
+	}
+	if term.Acquired {
+		t.Errorf("terminal still acquired after Open returned")
+	}
+	drawn := term.Output.Len()
+	finish <- struct{}{}
+	if term.Output.Len() != drawn {
+		t.Errorf("a callback drew to the terminal after it was handed back")
+	}
+}
+
+func TestOpenPanicReleases(t *testing.T) {
+	i := NewInteraction()
+	i.CreatePrompt("Home", "", false).AddOption("broken", "", nil).AddCallback(func(args ...any) (string, error) {
+		panic("callback broke")
+	})
+	term := NewFakeTerminal(keyEnter)
+	i.Terminal = term
+
+	defer func() {
+		if p := recover(); p != "callback broke" {
+			t.Errorf("recovered %v, want the callback's panic", p)
+		}
+		if term.Acquired || !strings.HasSuffix(term.Output.String(), showCursor) {
+			t.Errorf("terminal not handed back after a panic")
+		}
+	}()
+	i.Open()
+}
+
+func TestHintsFollowKeymap(t *testing.T) {
+	build := func(keymap Keymap) *Interaction {
+		i := NewInteraction()
+		i.Keymap = keymap
+		home := i.CreatePrompt("Home", "", true)
+		home.AddOption("a", "", nil).Selectable = true
+		return i
+	}
+
+	frames := open(t, build(DefaultKeymap()))
+	want := "[n] Next page | [b] Last page | [enter] Select | [space] Toggle | [i] Install selected | [esc] Exit"
+	if !strings.Contains(unwrap(frames[0]), want) {
+		t.Errorf("default hints: want %q in\n%s", want, frames[0])
+	}
+
+	vim := VimKeymap()
+	for _, b := range []string{"n=none", "b=none", "q=none", "x=toggle", "space=none"} {
+		if err := vim.Bind(b); err != nil {
+			t.Fatal(err)
+		}
+	}
+	frames = open(t, build(vim))
+	want = "[pgdown] Next page | [left] Last page | [enter] Select | [x] Toggle | [i] Install selected | [esc] Exit"
+	if !strings.Contains(unwrap(frames[0]), want) {
+		t.Errorf("rebound hints: want %q in\n%s", want, frames[0])
+	}
+}
+
+func TestHintsForSearchAndLabels(t *testing.T) {
+	i := NewInteraction()
+	i.Keymap = VimKeymap()
+	if err := i.Keymap.Bind("esc=none"); err != nil {
+		t.Fatal(err)
+	}
+	home, search := i.CreateSearchPrompt("Search")
+	search.AddToIndex(home.AddOption("nubby", "", nil))
+	child := i.CreatePrompt("Child", "", false)
+	child.AttachParent(home.Idx)
+	child.List.Labels = map[Action]string{ActionBack: "Back to list"}
+	child.AddOption("leaf", "", nil)
+	home.List.Options[0].AttachPrompt(child.Idx)
+
+	frames := open(t, i, keyTab, keyEnter)
+
+	// Keys that type into the search bar aren't offered for anything else while it has the focus
+	if want := "[down] Select results | [tab] Switch focus"; !strings.Contains(unwrap(frames[0]), want) || strings.Contains(frames[0], "Exit") {
+		t.Errorf("search bar hints: want only %q in\n%s", want, frames[0])
+	}
+	if want := "[+] Select search | [n] Next page | [b] Last page | [enter] Select package | [q] Exit"; !strings.Contains(unwrap(frames[1]), want) {
+		t.Errorf("results hints: want %q in\n%s", want, frames[1])
+	}
+	if want := "[enter] Select | [u] Back to list | [q] Exit"; !strings.Contains(unwrap(frames[2]), want) {
+		t.Errorf("child hints: want %q in\n%s", want, frames[2])
+	}
+}
@@ -0,0 +1,249 @@
+package interaction
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Action is what a key press does in a prompt.
+type Action int
+
+const (
+	ActionNone Action = iota
+	ActionUp
+	ActionDown
+	ActionNextPage
+	ActionPrevPage
+	ActionFirst
+	ActionLast
+	ActionSelect
+	ActionToggle
+	ActionBatch
+	ActionBack
+	ActionExit
+	ActionInterrupt // Exit the way SIGINT would, raw mode keeps ctrl+c from sending it
+	ActionSearch    // Focus the search bar
+	ActionResults   // Focus the list
+	ActionFocus     // Focus the next widget
+)
+
+var actionNames = map[Action]string{
+	ActionUp:        "up",
+	ActionDown:      "down",
+	ActionNextPage:  "next-page",
+	ActionPrevPage:  "prev-page",
+	ActionFirst:     "first",
+	ActionLast:      "last",
+	ActionSelect:    "select",
+	ActionToggle:    "toggle",
+	ActionBatch:     "batch",
+	ActionBack:      "back",
+	ActionExit:      "exit",
+	ActionInterrupt: "interrupt",
+	ActionSearch:    "search",
+	ActionResults:   "results",
+	ActionFocus:     "focus",
+}
+
+func (a Action) String() string {
+	return actionNames[a]
+}
+
+// Keymap binds key presses to actions. Typed characters go to a focused text input before the keymap
+// is looked at, so letters bound here still work in a search bar.
+type Keymap map[Key]Action
+
+// Keymaps are the presets that can be picked by name.
+var Keymaps = map[string]func() Keymap{
+	"default": DefaultKeymap,
+	"vim":     VimKeymap,
+	"emacs":   EmacsKeymap,
+}
+
+// KeymapNames lists the presets, sorted.
+func KeymapNames() []string {
+	names := make([]string, 0, len(Keymaps))
+	for name := range Keymaps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// DefaultKeymap is arrows and the single letter keys the menus have always used.
+func DefaultKeymap() Keymap {
+	return Keymap{
+		{Code: KeyUp}:              ActionUp,
+		{Code: KeyDown}:            ActionDown,
+		{Code: KeyRight}:           ActionNextPage,
+		{Code: KeyLeft}:            ActionPrevPage,
+		{Code: KeyPgDn}:            ActionNextPage,
+		{Code: KeyPgUp}:            ActionPrevPage,
+		{Code: KeyHome}:            ActionFirst,
+		{Code: KeyEnd}:             ActionLast,
+		{Code: KeyEnter}:           ActionSelect,
+		{Code: KeyEsc}:             ActionExit,
+		{Code: KeyCtrl, Rune: 'c'}: ActionInterrupt,
+		{Code: KeyTab}:             ActionFocus,
+		{Code: KeyRune, Rune: 'n'}: ActionNextPage,
+		{Code: KeyRune, Rune: 'b'}: ActionPrevPage,
+		{Code: KeyRune, Rune: 'u'}: ActionBack,
+		{Code: KeyRune, Rune: ' '}: ActionToggle,
+		{Code: KeyRune, Rune: 'i'}: ActionBatch,
+		{Code: KeyRune, Rune: '+'}: ActionSearch,
+		{Code: KeyRune, Rune: '/'}: ActionSearch,
+		{Code: KeyRune, Rune: '='}: ActionResults,
+	}
+}
+
+// VimKeymap adds hjkl movement, ctrl+f/ctrl+b paging, g/G and q on top of the defaults.
+func VimKeymap() Keymap {
+	k := DefaultKeymap()
+	k.merge(Keymap{
+		{Code: KeyRune, Rune: 'j'}: ActionDown,
+		{Code: KeyRune, Rune: 'k'}: ActionUp,
+		{Code: KeyRune, Rune: 'h'}: ActionBack,
+		{Code: KeyRune, Rune: 'l'}: ActionSelect,
+		{Code: KeyRune, Rune: 'g'}: ActionFirst,
+		{Code: KeyRune, Rune: 'G'}: ActionLast,
+		{Code: KeyRune, Rune: 'q'}: ActionExit,
+		{Code: KeyCtrl, Rune: 'f'}: ActionNextPage,
+		{Code: KeyCtrl, Rune: 'b'}: ActionPrevPage,
+		{Code: KeyCtrl, Rune: 'd'}: ActionNextPage,
+		{Code: KeyCtrl, Rune: 'u'}: ActionPrevPage,
+		{Code: KeyRune, Rune: 'x'}: ActionToggle,
+		{Code: KeyCtrl, Rune: 'o'}: ActionBack,
+	})
+	return k
+}
+
+// EmacsKeymap adds ctrl+n/ctrl+p movement, ctrl+v/alt+v paging, alt+< and alt+> and ctrl+g on top of the defaults.
+func EmacsKeymap() Keymap {
+	k := DefaultKeymap()
+	k.merge(Keymap{
+		{Code: KeyCtrl, Rune: 'n'}:            ActionDown,
+		{Code: KeyCtrl, Rune: 'p'}:            ActionUp,
+		{Code: KeyCtrl, Rune: 'v'}:            ActionNextPage,
+		{Code: KeyRune, Rune: 'v', Alt: true}: ActionPrevPage,
+		{Code: KeyRune, Rune: '<', Alt: true}: ActionFirst,
+		{Code: KeyRune, Rune: '>', Alt: true}: ActionLast,
+		{Code: KeyCtrl, Rune: 'g'}:            ActionExit,
+		{Code: KeyCtrl, Rune: 'f'}:            ActionSelect,
+		{Code: KeyCtrl, Rune: 'b'}:            ActionBack,
+		{Code: KeyCtrl, Rune: 's'}:            ActionSearch,
+	})
+	return k
+}
+
+func (k Keymap) merge(other Keymap) {
+	for key, action := range other {
+		k[key] = action
+	}
+}
+
+// Bind reads a binding like "ctrl+j=down" and adds it, replacing whatever the key did before.
+// Binding a key to "none" unbinds it.
+func (k Keymap) Bind(binding string) error {
+	// Split on the last = so = itself can be bound
+	at := strings.LastIndex(binding, "=")
+	if at <= 0 {
+		return fmt.Errorf("binding %q should look like key=action", binding)
+	}
+	keyName, actionName := binding[:at], binding[at+1:]
+	key, err := ParseKey(keyName)
+	if err != nil {
+		return err
+	}
+
+	if actionName == "none" {
+		delete(k, key)
+		return nil
+	}
+	for action, name := range actionNames {
+		if name == actionName {
+			k[key] = action
+			return nil
+		}
+	}
+
+	names := make([]string, 0, len(actionNames))
+	for _, name := range actionNames {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return fmt.Errorf("unknown action %q, expected one of %s", actionName, strings.Join(names, ", "))
+}
+
+// Keys lists the keys bound to action, in the order hints prefer them: keys the default keymap uses for
+// it first so hints look the same across presets, then single characters, named keys like enter, and
+// ctrl and alt combinations.
+func (k Keymap) Keys(action Action) []Key {
+	defaults := DefaultKeymap()
+	rank := func(key Key) int {
+		r := 0
+		switch {
+		case key.Alt:
+			r = 3
+		case key.Code == KeyCtrl:
+			r = 2
+		case key.Code != KeyRune:
+			r = 1
+		}
+		if defaults[key] != action {
+			r += 4
+		}
+		return r
+	}
+
+	keys := []Key{}
+	for key, a := range k {
+		if a == action {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if rank(keys[i]) != rank(keys[j]) {
+			return rank(keys[i]) < rank(keys[j])
+		}
+		return keys[i].String() < keys[j].String()
+	})
+	return keys
+}
+
+// Hint is one entry in a prompt's key hints, what an action does there.
+type Hint struct {
+	Action Action
+	Label  string
+}
+
+// Hints describes each action as "[key] Label", joined with " | ", using the first of Keys for it.
+// Actions with no key bound are left out.
+func (k Keymap) Hints(hints ...Hint) string {
+	return k.hints(false, hints)
+}
+
+// TypingHints is Hints for widgets that take typed characters themselves, so keys that type one are
+// left out of the hints, whatever they are bound to.
+func (k Keymap) TypingHints(hints ...Hint) string {
+	return k.hints(true, hints)
+}
+
+func (k Keymap) hints(typing bool, hints []Hint) string {
+	parts := []string{}
+	for _, h := range hints {
+		for _, key := range k.Keys(h.Action) {
+			if typing && key.Code == KeyRune && !key.Alt {
+				continue
+			}
+			parts = append(parts, "["+key.String()+"] "+h.Label)
+			break
+		}
+	}
+	return strings.Join(parts, " | ")
+}
+
+// Action is what key does, ActionNone if it isn't bound.
+func (k Keymap) Action(key Key) Action {
+	return k[key]
+}
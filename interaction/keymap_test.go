@@ -0,0 +1,116 @@
+package interaction
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBind(t *testing.T) {
+	k := DefaultKeymap()
+	bindings := []string{"ctrl+j=down", "==select", "alt+ctrl+x=exit", "space=none", "q=exit"}
+	for _, b := range bindings {
+		if err := k.Bind(b); err != nil {
+			t.Fatalf("Bind(%q): %v", b, err)
+		}
+	}
+
+	tests := []struct {
+		key    Key
+		action Action
+	}{
+		{Key{Code: KeyCtrl, Rune: 'j'}, ActionDown},
+		{Key{Code: KeyRune, Rune: '='}, ActionSelect}, // Split on the last =, so = itself can be bound
+		{Key{Code: KeyCtrl, Rune: 'x', Alt: true}, ActionExit},
+		{Key{Code: KeyRune, Rune: ' '}, ActionNone},
+		{Key{Code: KeyRune, Rune: 'q'}, ActionExit},
+		{Key{Code: KeyUp}, ActionUp}, // Untouched
+	}
+	for _, tt := range tests {
+		if got := k.Action(tt.key); got != tt.action {
+			t.Errorf("%s does %v, want %v", tt.key, got, tt.action)
+		}
+	}
+	if _, ok := k[Key{Code: KeyRune, Rune: ' '}]; ok {
+		t.Errorf("=none should remove the binding, not bind ActionNone")
+	}
+}
+
+func TestBindErrors(t *testing.T) {
+	tests := []struct {
+		binding string
+		message string
+	}{
+		{"down", "should look like key=action"},
+		{"=down", "should look like key=action"},
+		{"ctrl+j=dwon", `unknown action "dwon"`},
+		{"ctrl+j=", `unknown action ""`},
+		{"pageup=down", `unknown key "pageup"`},
+	}
+	for _, tt := range tests {
+		k := DefaultKeymap()
+		err := k.Bind(tt.binding)
+		if err == nil || !strings.Contains(err.Error(), tt.message) {
+			t.Errorf("Bind(%q) = %v, want an error saying %q", tt.binding, err, tt.message)
+		}
+		if len(k) != len(DefaultKeymap()) {
+			t.Errorf("Bind(%q) changed the keymap despite failing", tt.binding)
+		}
+	}
+
+	// The error lists what could have been meant
+	err := DefaultKeymap().Bind("x=dwon")
+	for _, name := range actionNames {
+		if !strings.Contains(err.Error(), name) {
+			t.Errorf("error does not list %q: %v", name, err)
+		}
+	}
+}
+
+func TestActionNamesRoundTrip(t *testing.T) {
+	for action, name := range actionNames {
+		k := Keymap{}
+		if err := k.Bind("x=" + name); err != nil || k.Action(Key{Code: KeyRune, Rune: 'x'}) != action {
+			t.Errorf("Bind(x=%s) = %v, bound %v, want %v", name, err, k.Action(Key{Code: KeyRune, Rune: 'x'}), action)
+		}
+		if action.String() != name {
+			t.Errorf("%d.String() = %q, want %q", action, action.String(), name)
+		}
+	}
+}
+
+func TestPresets(t *testing.T) {
+	tests := []struct {
+		preset string
+		key    string
+		action Action
+	}{
+		{"default", "n", ActionNextPage},
+		{"default", "ctrl+c", ActionInterrupt},
+		{"default", "esc", ActionExit},
+		{"vim", "j", ActionDown},
+		{"vim", "k", ActionUp},
+		{"vim", "G", ActionLast},
+		{"vim", "ctrl+f", ActionNextPage},
+		{"vim", "q", ActionExit},
+		{"vim", "esc", ActionExit}, // Presets build on the defaults
+		{"emacs", "ctrl+n", ActionDown},
+		{"emacs", "ctrl+p", ActionUp},
+		{"emacs", "alt+v", ActionPrevPage},
+		{"emacs", "alt+<", ActionFirst},
+		{"emacs", "ctrl+g", ActionExit},
+		{"emacs", "ctrl+c", ActionInterrupt},
+	}
+	for _, tt := range tests {
+		key, err := ParseKey(tt.key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := Keymaps[tt.preset]().Action(key); got != tt.action {
+			t.Errorf("%s: %s does %v, want %v", tt.preset, tt.key, got, tt.action)
+		}
+	}
+
+	if names := strings.Join(KeymapNames(), ","); names != "default,emacs,vim" {
+		t.Errorf("KeymapNames() = %s", names)
+	}
+}
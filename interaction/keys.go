@@ -0,0 +1,235 @@
+package interaction
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// KeyCode is which key was pressed, typed text is KeyRune.
+type KeyCode int
+
+const (
+	KeyRune KeyCode = iota
+	KeyUp
+	KeyDown
+	KeyLeft
+	KeyRight
+	KeyPgUp
+	KeyPgDn
+	KeyHome
+	KeyEnd
+	KeyEnter
+	KeyEsc
+	KeyBackspace
+	KeyDelete
+	KeyTab
+	KeyCtrl // Ctrl held with the letter in Rune
+)
+
+var keyNames = map[KeyCode]string{
+	KeyUp:        "up",
+	KeyDown:      "down",
+	KeyLeft:      "left",
+	KeyRight:     "right",
+	KeyPgUp:      "pgup",
+	KeyPgDn:      "pgdown",
+	KeyHome:      "home",
+	KeyEnd:       "end",
+	KeyEnter:     "enter",
+	KeyEsc:       "esc",
+	KeyBackspace: "backspace",
+	KeyDelete:    "delete",
+	KeyTab:       "tab",
+}
+
+// Key is one key press. Keys are comparable, so they can be looked up in a Keymap.
+type Key struct {
+	Code KeyCode
+	Rune rune // The character typed, or the letter for KeyCtrl
+	Alt  bool // Alt (or Esc right before it) was held
+}
+
+// Runes is the key presses for typing s.
+func Runes(s string) []Key {
+	keys := make([]Key, 0, len(s))
+	for _, r := range s {
+		keys = append(keys, Key{Code: KeyRune, Rune: r})
+	}
+	return keys
+}
+
+// String names the key the way ParseKey reads it, e.g. "up", "ctrl+n", "alt+v" or "q".
+func (k Key) String() string {
+	var name string
+	switch k.Code {
+	case KeyRune:
+		name = string(k.Rune)
+		if k.Rune == ' ' {
+			name = "space"
+		}
+	case KeyCtrl:
+		name = "ctrl+" + string(k.Rune)
+	default:
+		name = keyNames[k.Code]
+	}
+	if k.Alt {
+		name = "alt+" + name
+	}
+	return name
+}
+
+// ParseKey reads a key name as written by Key.String.
+func ParseKey(name string) (Key, error) {
+	var k Key
+	rest := name
+	if after, ok := strings.CutPrefix(rest, "alt+"); ok && after != "" {
+		k.Alt = true
+		rest = after
+	}
+	if after, ok := strings.CutPrefix(rest, "ctrl+"); ok && utf8.RuneCountInString(after) == 1 {
+		k.Code = KeyCtrl
+		k.Rune = []rune(strings.ToLower(after))[0]
+		return k, nil
+	}
+	if rest == "space" {
+		return Key{Code: KeyRune, Rune: ' ', Alt: k.Alt}, nil
+	}
+	for code, v := range keyNames {
+		if v == rest {
+			k.Code = code
+			return k, nil
+		}
+	}
+	if utf8.RuneCountInString(rest) == 1 {
+		k.Code = KeyRune
+		k.Rune = []rune(rest)[0]
+		return k, nil
+	}
+	return Key{}, fmt.Errorf("unknown key %q", name)
+}
+
+// Decoder turns the bytes a terminal sends into key presses. Terminals send each key press or paste
+// in a single write, so a lone escape byte at the end of what was read is the Esc key itself rather
+// than the start of an escape sequence. A UTF-8 character split across reads is kept until the rest arrives.
+type Decoder struct {
+	pending []byte
+}
+
+// Decode returns the key presses in p, in order.
+func (d *Decoder) Decode(p []byte) []Key {
+	buf := append(d.pending, p...)
+	d.pending = nil
+
+	keys := []Key{}
+	for len(buf) > 0 {
+		key, size, ok := decodeKey(buf)
+		if !ok {
+			d.pending = append([]byte{}, buf...)
+			break
+		}
+		buf = buf[size:]
+		if size > 0 && key != (Key{}) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// decodeKey reads the first key press in buf and how many bytes it took. ok is false when buf ends
+// part way through a UTF-8 character. A zero Key means the bytes were skipped, e.g. an unknown sequence.
+func decodeKey(buf []byte) (key Key, size int, ok bool) {
+	c := buf[0]
+	switch {
+	case c == 0x1b:
+		if len(buf) == 1 {
+			return Key{Code: KeyEsc}, 1, true
+		}
+		if buf[1] == '[' || buf[1] == 'O' {
+			key, size := decodeSequence(buf)
+			return key, size, true
+		}
+		// Esc then another key is how terminals send Alt with it
+		key, size, ok := decodeKey(buf[1:])
+		if !ok || key == (Key{}) {
+			return Key{Code: KeyEsc}, 1, true
+		}
+		if key.Code == KeyEsc {
+			return Key{Code: KeyEsc}, 1, true
+		}
+		key.Alt = true
+		return key, size + 1, true
+	case c == '\r' || c == '\n':
+		return Key{Code: KeyEnter}, 1, true
+	case c == '\t':
+		return Key{Code: KeyTab}, 1, true
+	case c == 0x7f || c == 0x08:
+		return Key{Code: KeyBackspace}, 1, true
+	case c >= 0x01 && c <= 0x1a:
+		return Key{Code: KeyCtrl, Rune: rune('a' + c - 1)}, 1, true
+	case c < 0x20:
+		return Key{}, 1, true
+	}
+
+	if !utf8.FullRune(buf) {
+		return Key{}, 0, false
+	}
+	r, size := utf8.DecodeRune(buf)
+	if r == utf8.RuneError {
+		return Key{}, size, true
+	}
+	return Key{Code: KeyRune, Rune: r}, size, true
+}
+
+// decodeSequence reads a CSI (<esc>[) or SS3 (<esc>O) sequence, e.g. <esc>[A for up or <esc>[5~ for
+// page up. Modifiers like the ;5 in <esc>[1;5A are dropped.
+func decodeSequence(buf []byte) (Key, int) {
+	ss3 := buf[1] == 'O'
+
+	// Parameters run up to the final byte, a letter or ~
+	end := 2
+	for end < len(buf) && (buf[end] < 0x40 || buf[end] > 0x7e) {
+		end++
+	}
+	if end == len(buf) {
+		return Key{}, len(buf)
+	}
+	final := buf[end]
+	params := string(buf[2:end])
+	size := end + 1
+
+	switch final {
+	case 'A':
+		return Key{Code: KeyUp}, size
+	case 'B':
+		return Key{Code: KeyDown}, size
+	case 'C':
+		return Key{Code: KeyRight}, size
+	case 'D':
+		return Key{Code: KeyLeft}, size
+	case 'H':
+		return Key{Code: KeyHome}, size
+	case 'F':
+		return Key{Code: KeyEnd}, size
+	case 'Z':
+		if !ss3 {
+			// Shift+Tab
+			return Key{Code: KeyTab}, size
+		}
+	case '~':
+		number, _, _ := strings.Cut(params, ";")
+		switch number {
+		case "1", "7":
+			return Key{Code: KeyHome}, size
+		case "4", "8":
+			return Key{Code: KeyEnd}, size
+		case "3":
+			return Key{Code: KeyDelete}, size
+		case "5":
+			return Key{Code: KeyPgUp}, size
+		case "6":
+			return Key{Code: KeyPgDn}, size
+		}
+	}
+	return Key{}, size
+}
@@ -0,0 +1,116 @@
+package interaction
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDecode(t *testing.T) {
+	var (
+		up    = Key{Code: KeyUp}
+		esc   = Key{Code: KeyEsc}
+		enter = Key{Code: KeyEnter}
+	)
+	char := func(r rune) Key { return Key{Code: KeyRune, Rune: r} }
+
+	tests := []struct {
+		name  string
+		reads []string // What each read from the terminal returned
+		want  []Key
+	}{
+		{"letter", []string{"q"}, []Key{char('q')}},
+		{"space", []string{" "}, []Key{char(' ')}},
+		{"lone esc", []string{"\x1b"}, []Key{esc}},
+		{"esc then a key in the next read", []string{"\x1b", "n"}, []Key{esc, char('n')}},
+		{"esc twice", []string{"\x1b\x1b"}, []Key{esc, esc}},
+		{"alt+key", []string{"\x1bv"}, []Key{{Code: KeyRune, Rune: 'v', Alt: true}}},
+		{"alt+<", []string{"\x1b<"}, []Key{{Code: KeyRune, Rune: '<', Alt: true}}},
+		{"alt+ctrl", []string{"\x1b\x0e"}, []Key{{Code: KeyCtrl, Rune: 'n', Alt: true}}},
+		{"alt+enter", []string{"\x1b\r"}, []Key{{Code: KeyEnter, Alt: true}}},
+		{"csi arrows", []string{"\x1b[A\x1b[B\x1b[C\x1b[D"}, []Key{up, {Code: KeyDown}, {Code: KeyRight}, {Code: KeyLeft}}},
+		{"ss3 arrows", []string{"\x1bOA\x1bOD"}, []Key{up, {Code: KeyLeft}}},
+		{"csi home and end", []string{"\x1b[H\x1b[F"}, []Key{{Code: KeyHome}, {Code: KeyEnd}}},
+		{"ss3 home and end", []string{"\x1bOH\x1bOF"}, []Key{{Code: KeyHome}, {Code: KeyEnd}}},
+		{"modifiers dropped", []string{"\x1b[1;5A"}, []Key{up}},
+		{"pgup", []string{"\x1b[5~"}, []Key{{Code: KeyPgUp}}},
+		{"pgdown", []string{"\x1b[6~"}, []Key{{Code: KeyPgDn}}},
+		{"delete", []string{"\x1b[3~"}, []Key{{Code: KeyDelete}}},
+		{"home ~ sequences", []string{"\x1b[1~\x1b[7~"}, []Key{{Code: KeyHome}, {Code: KeyHome}}},
+		{"end ~ sequences", []string{"\x1b[4~\x1b[8~"}, []Key{{Code: KeyEnd}, {Code: KeyEnd}}},
+		{"modified ~ sequence", []string{"\x1b[5;2~"}, []Key{{Code: KeyPgUp}}},
+		{"shift+tab", []string{"\x1b[Z"}, []Key{{Code: KeyTab}}},
+		{"unknown sequence skipped", []string{"\x1b[99~x"}, []Key{char('x')}},
+		{"unfinished sequence skipped", []string{"\x1b[1;"}, []Key{}},
+		{"enter", []string{"\r\n"}, []Key{enter, enter}},
+		{"tab", []string{"\t"}, []Key{{Code: KeyTab}}},
+		{"backspace", []string{"\x7f\x08"}, []Key{{Code: KeyBackspace}, {Code: KeyBackspace}}},
+		{"ctrl combos", []string{"\x03\x0e\x10\x1a"}, []Key{
+			{Code: KeyCtrl, Rune: 'c'}, {Code: KeyCtrl, Rune: 'n'}, {Code: KeyCtrl, Rune: 'p'}, {Code: KeyCtrl, Rune: 'z'},
+		}},
+		{"other control bytes skipped", []string{"\x00\x1c\x1fa"}, []Key{char('a')}},
+		{"paste", []string{"go get"}, Runes("go get")},
+		{"paste with keys in it", []string{"ab\x1b[Ac\r"}, []Key{char('a'), char('b'), up, char('c'), enter}},
+		{"utf-8", []string{"é世"}, []Key{char('é'), char('世')}},
+		{"utf-8 split across reads", []string{"a\xc3", "\xa9b"}, []Key{char('a'), char('é'), char('b')}},
+		{"utf-8 split three ways", []string{"\xe4", "\xb8", "\x96"}, []Key{char('世')}},
+		{"invalid utf-8 skipped", []string{"\xffa"}, []Key{char('a')}},
+	}
+	for _, tt := range tests {
+		var d Decoder
+		got := []Key{}
+		for _, read := range tt.reads {
+			got = append(got, d.Decode([]byte(read))...)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: Decode(%q) = %v, want %v", tt.name, tt.reads, got, tt.want)
+		}
+	}
+}
+
+func TestKeyNames(t *testing.T) {
+	tests := []struct {
+		key  Key
+		name string
+	}{
+		{Key{Code: KeyRune, Rune: 'q'}, "q"},
+		{Key{Code: KeyRune, Rune: 'G'}, "G"},
+		{Key{Code: KeyRune, Rune: '='}, "="},
+		{Key{Code: KeyRune, Rune: ' '}, "space"},
+		{Key{Code: KeyRune, Rune: 'é'}, "é"},
+		{Key{Code: KeyRune, Rune: 'v', Alt: true}, "alt+v"},
+		{Key{Code: KeyRune, Rune: ' ', Alt: true}, "alt+space"},
+		{Key{Code: KeyCtrl, Rune: 'n'}, "ctrl+n"},
+		{Key{Code: KeyCtrl, Rune: 'n', Alt: true}, "alt+ctrl+n"},
+		{Key{Code: KeyUp}, "up"},
+		{Key{Code: KeyPgDn}, "pgdown"},
+		{Key{Code: KeyEnter, Alt: true}, "alt+enter"},
+		{Key{Code: KeyEsc}, "esc"},
+	}
+	for _, tt := range tests {
+		if got := tt.key.String(); got != tt.name {
+			t.Errorf("%#v.String() = %q, want %q", tt.key, got, tt.name)
+		}
+		got, err := ParseKey(tt.name)
+		if err != nil || got != tt.key {
+			t.Errorf("ParseKey(%q) = %#v, %v, want %#v", tt.name, got, err, tt.key)
+		}
+	}
+
+	// Every named key survives the round trip
+	for code, name := range keyNames {
+		if got, err := ParseKey(name); err != nil || got != (Key{Code: code}) || got.String() != name {
+			t.Errorf("ParseKey(%q) = %#v, %v", name, got, err)
+		}
+	}
+}
+
+func TestParseKeyErrors(t *testing.T) {
+	if k, err := ParseKey("ctrl+N"); err != nil || k != (Key{Code: KeyCtrl, Rune: 'n'}) {
+		t.Errorf("ctrl+N should read as ctrl+n, got %#v, %v", k, err)
+	}
+	for _, name := range []string{"", "pageup", "ctrl+", "ctrl+ab", "alt+", "qq"} {
+		if k, err := ParseKey(name); err == nil {
+			t.Errorf("ParseKey(%q) = %#v, want an error", name, k)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package interaction
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/width"
+)
+
+// ellipsis marks text cut short to fit the width of the terminal.
+const ellipsis = "…"
+
+// reset turns off any color or bold left on by text cut short part way through.
+const reset = "\033[0m"
+
+// visibleWidth is how many cells s takes up, not counting color escape sequences.
+func visibleWidth(s string) int {
+	width := 0
+	for j := 0; j < len(s); {
+		if n := escapeLen(s[j:]); n > 0 {
+			j += n
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[j:])
+		j += size
+		width += runeWidth(r)
+	}
+	return width
+}
+
+// runeWidth is how many cells r takes up. Wide and fullwidth East Asian characters take two,
+// everything else one.
+func runeWidth(r rune) int {
+	switch width.LookupRune(r).Kind() {
+	case width.EastAsianWide, width.EastAsianFullwidth:
+		return 2
+	}
+	return 1
+}
+
+// cut splits plain text s after as many characters as fit in cells, always taking at least one.
+func cut(s string, cells int) (string, string) {
+	used := 0
+	for j, r := range s {
+		used += runeWidth(r)
+		if j > 0 && used > cells {
+			return s[:j], s[j:]
+		}
+	}
+	return s, ""
+}
+
+// lastCells is the end of plain text s that fits in cells.
+func lastCells(s string, cells int) string {
+	used := 0
+	for j := len(s); j > 0; {
+		r, size := utf8.DecodeLastRuneInString(s[:j])
+		if used += runeWidth(r); used > cells {
+			return s[j:]
+		}
+		j -= size
+	}
+	return s
+}
+
+// escapeLen is the length of the escape sequence s starts with, 0 if it doesn't start with one.
+func escapeLen(s string) int {
+	if len(s) < 2 || s[0] != 0x1b || s[1] != '[' {
+		return 0
+	}
+	for j := 2; j < len(s); j++ {
+		if s[j] >= 0x40 && s[j] <= 0x7e {
+			return j + 1
+		}
+	}
+	return len(s)
+}
+
+// truncate cuts s down to width cells, ending it with an ellipsis when anything was cut.
+// Escape sequences are kept whole and don't count towards the width.
+func truncate(s string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	if visibleWidth(s) <= width {
+		return s
+	}
+
+	var out strings.Builder
+	cells := 0
+	for j := 0; j < len(s); {
+		if n := escapeLen(s[j:]); n > 0 {
+			out.WriteString(s[j : j+n])
+			j += n
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[j:])
+		if cells+runeWidth(r) > width-1 {
+			break
+		}
+		out.WriteString(s[j : j+size])
+		j += size
+		cells += runeWidth(r)
+	}
+	out.WriteString(ellipsis + reset)
+	return out.String()
+}
+
+// wrap breaks plain text into lines of at most width cells, between words where it can.
+// Existing line breaks are kept, and so is the indentation a line starts with.
+func wrap(s string, width int) []string {
+	if width <= 0 {
+		return nil
+	}
+
+	lines := []string{}
+	for _, paragraph := range strings.Split(s, "\n") {
+		// Indentation only goes on the first line, and only if it leaves room for the text
+		indent := paragraph[:len(paragraph)-len(strings.TrimLeft(paragraph, " \t"))]
+		if visibleWidth(indent) >= width/2 {
+			indent = ""
+		}
+
+		line, used, started := indent, visibleWidth(indent), false
+		for _, word := range strings.Fields(paragraph) {
+			cells := visibleWidth(word)
+			switch {
+			case started && used+1+cells <= width:
+				line, used = line+" "+word, used+1+cells
+				continue
+			case started:
+				lines = append(lines, line)
+				line, used = "", 0
+			case used+cells > width && cells <= width:
+				// The first word fits on a line, just not next to the indentation
+				line, used = "", 0
+			}
+
+			// Words longer than a whole line get split wherever they hit the edge
+			for used+cells > width {
+				head, rest := cut(word, width-used)
+				lines = append(lines, line+head)
+				line, used = "", 0
+				word, cells = rest, visibleWidth(rest)
+			}
+			line, used, started = line+word, used+cells, true
+		}
+		if !started {
+			line = ""
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// clip keeps the first limit lines, marking the last one kept when any were dropped.
+func clip(lines []string, limit int) []string {
+	if limit <= 0 || len(lines) <= limit {
+		return lines
+	}
+	lines = lines[:limit]
+	last := []rune(lines[limit-1])
+	lines[limit-1] = strings.TrimRight(string(last[:max(0, len(last)-1)]), " ") + ellipsis
+	return lines
+}
+
+// countLines is how many lines a rendered widget took up.
+func countLines(s string) int {
+	return strings.Count(s, "\n")
+}
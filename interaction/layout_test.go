@@ -0,0 +1,106 @@
+package interaction
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestVisibleWidth(t *testing.T) {
+	tests := []struct {
+		s     string
+		width int
+	}{
+		{"", 0},
+		{"hello", 5},
+		{"\033[1m\033[36mhello\033[0m", 5},
+		{"日本語", 6},
+		{"ｇｏ", 4},   // Fullwidth latin
+		{"ｶﾀｶﾅ", 4}, // Halfwidth katakana
+		{"go 言語", 7},
+		{"╭┈╯", 3},
+	}
+	for _, tt := range tests {
+		if got := visibleWidth(tt.s); got != tt.width {
+			t.Errorf("visibleWidth(%q) = %d, want %d", tt.s, got, tt.width)
+		}
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		s     string
+		width int
+		want  string
+	}{
+		{"hello", 5, "hello"},
+		{"hello world", 5, "hell…" + reset},
+		{"\033[1mhello world\033[0m", 5, "\033[1mhell…" + reset},
+		{"日本語のテキスト", 8, "日本語…" + reset},
+		// A wide character that would end up half past the edge is left out
+		{"日本語のテキスト", 7, "日本語…" + reset},
+		{"hello", 0, ""},
+	}
+	for _, tt := range tests {
+		got := truncate(tt.s, tt.width)
+		if got != tt.want {
+			t.Errorf("truncate(%q, %d) = %q, want %q", tt.s, tt.width, got, tt.want)
+		}
+		if w := visibleWidth(got); w > tt.width {
+			t.Errorf("truncate(%q, %d) is %d wide", tt.s, tt.width, w)
+		}
+	}
+}
+
+func TestWrap(t *testing.T) {
+	tests := []struct {
+		s     string
+		width int
+		want  []string
+	}{
+		{"hello world", 20, []string{"hello world"}},
+		{"hello world", 5, []string{"hello", "world"}},
+		{"one\n\ntwo", 20, []string{"one", "", "two"}},
+		// Indentation stays on the first line
+		{"  hello world", 20, []string{"  hello world"}},
+		{"  hello world", 10, []string{"  hello", "world"}},
+		// A first word that only fits without the indentation goes on a line of its own rather than being split
+		{"  hello world", 6, []string{"hello", "world"}},
+		// Indentation that leaves too little room for the text is dropped
+		{"      hello", 10, []string{"hello"}},
+		// Words longer than a line are split at the edge
+		{"a abcdefghij", 4, []string{"a", "abcd", "efgh", "ij"}},
+		{"  abcdefghijk", 6, []string{"  abcd", "efghij", "k"}},
+		// Wide characters take two cells
+		{"日本語 テキスト", 8, []string{"日本語", "テキスト"}},
+		{"日本語のテキスト", 5, []string{"日本", "語の", "テキ", "スト"}},
+		{"   ", 10, []string{""}},
+	}
+	for _, tt := range tests {
+		got := wrap(tt.s, tt.width)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("wrap(%q, %d) = %q, want %q", tt.s, tt.width, got, tt.want)
+		}
+		for _, line := range got {
+			if w := visibleWidth(line); w > tt.width {
+				t.Errorf("wrap(%q, %d) has a line %d wide: %q", tt.s, tt.width, w, line)
+			}
+		}
+	}
+}
+
+func TestTextInputKeepsEndInView(t *testing.T) {
+	for _, value := range []string{strings.Repeat("a", 40), strings.Repeat("語", 40)} {
+		input := &TextInput{Label: "Search", Value: value}
+		var out strings.Builder
+		input.Render(&out, NewInteraction(), false, 20)
+		for _, line := range strings.Split(plain(out.String()), "\n") {
+			if w := visibleWidth(line); w > 20 {
+				t.Errorf("line is %d wide on a 20 column terminal: %q", w, line)
+			}
+		}
+		if !strings.Contains(out.String(), ellipsis+value[len(value)-3:]) {
+			t.Errorf("the end of %q should be in view:\n%s", value, out.String())
+		}
+	}
+}
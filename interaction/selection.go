@@ -0,0 +1,57 @@
+package interaction
+
+// Selection is the set of options picked with [space]. It lives on the interaction rather than a prompt,
+// so picks are kept while paging and moving between prompts, until [i] runs the batch action on them.
+type Selection struct {
+	Selected    []*Option
+	BatchAction func(selected []*Option) (string, error)
+}
+
+// Toggle selects or unselects an option. Options that aren't Selectable are ignored.
+func (s *Selection) Toggle(o *Option) {
+	if o == nil || !o.Selectable {
+		return
+	}
+
+	o.Selected = !o.Selected
+	if o.Selected {
+		s.Selected = append(s.Selected, o)
+		return
+	}
+	for j, v := range s.Selected {
+		if v == o {
+			s.Selected = append(s.Selected[:j], s.Selected[j+1:]...)
+			break
+		}
+	}
+}
+
+// RunBatch hands everything selected to the batch action, in the order it was picked, and clears the selection.
+func (s *Selection) RunBatch() (string, error) {
+	if s.BatchAction == nil {
+		return "Nothing can be done with a selection here.", nil
+	}
+	if len(s.Selected) == 0 {
+		return "Nothing selected, press [space] on a package to select it.", nil
+	}
+
+	selected := s.Selected
+	for _, o := range selected {
+		o.Selected = false
+	}
+	s.Selected = nil
+
+	return s.BatchAction(selected)
+}
+
+// checkbox is drawn in front of selectable options.
+func checkbox(o *Option) string {
+	switch {
+	case !o.Selectable:
+		return ""
+	case o.Selected:
+		return "[x] "
+	default:
+		return "[ ] "
+	}
+}
@@ -0,0 +1,194 @@
+package interaction
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/buger/goterm"
+	"github.com/pkg/term"
+)
+
+// Terminal is what an Interaction reads key presses from and draws to.
+type Terminal interface {
+	io.Writer
+	// Acquire takes the terminal over for an interaction, e.g. putting it in raw mode, until Release.
+	Acquire() error
+	// Release puts the terminal back the way Acquire found it. Calling it again does nothing.
+	Release() error
+	// ReadKey blocks until the next key press. An error ends the interaction.
+	ReadKey() (Key, error)
+	// Size is the width and height of the terminal in cells.
+	Size() (width int, height int)
+}
+
+// Escape sequences for drawing frames. Rather than clearing the screen, which flickers, each frame is
+// drawn over the last one from the top left, clearing what is left of every line and everything below.
+const (
+	clearScreen = "\033[2J"
+	frameStart  = "\033[H"
+	clearLine   = "\033[K"
+	clearBelow  = "\033[J"
+	hideCursor  = "\033[?25l"
+	showCursor  = "\033[?25h"
+)
+
+// Fallback size for when the terminal can't be asked.
+const (
+	defaultWidth  = 80
+	defaultHeight = 24
+)
+
+// TTY is the terminal the process is running in, keys are read from Path and frames go to stdout.
+// It is opened and put in raw mode once, by Acquire.
+type TTY struct {
+	Path string
+	Out  io.Writer
+
+	mu      sync.Mutex // Guards tty, which Release can close while a read is waiting
+	tty     *term.Term
+	decoder Decoder
+	queue   []Key // Decoded but not yet read, a paste is many keys in one read
+}
+
+func NewTTY() *TTY {
+	return &TTY{Path: "/dev/tty", Out: os.Stdout}
+}
+
+func (t *TTY) Write(p []byte) (int, error) {
+	return t.Out.Write(p)
+}
+
+func (t *TTY) ReadKey() (Key, error) {
+	for len(t.queue) == 0 {
+		keys, err := t.read()
+		if err != nil {
+			return Key{}, err
+		}
+		t.queue = keys
+	}
+
+	key := t.queue[0]
+	t.queue = t.queue[1:]
+	return key, nil
+}
+
+// read waits for the terminal to send something and decodes it.
+func (t *TTY) read() ([]Key, error) {
+	t.mu.Lock()
+	tty := t.tty
+	t.mu.Unlock()
+	if tty == nil {
+		return nil, ErrNotAcquired
+	}
+
+	buf := make([]byte, 256)
+	read, err := tty.Read(buf)
+	if err != nil {
+		return nil, err
+	}
+	return t.decoder.Decode(buf[:read]), nil
+}
+
+func (t *TTY) Acquire() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.tty != nil {
+		return nil
+	}
+	tty, err := term.Open(t.Path, term.RawMode)
+	if err != nil {
+		return err
+	}
+	t.tty = tty
+	return nil
+}
+
+func (t *TTY) Release() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.tty == nil {
+		return nil
+	}
+	err := t.tty.Restore()
+	t.tty.Close()
+	t.tty = nil
+	return err
+}
+
+func (t *TTY) Size() (int, int) {
+	width, height := goterm.Width(), goterm.Height()
+	if width <= 0 || height <= 0 {
+		return defaultWidth, defaultHeight
+	}
+	return width, height
+}
+
+// ErrNotAcquired means keys were read from a terminal before Acquire was called.
+var ErrNotAcquired = errors.New("terminal has not been acquired")
+
+// ErrNoMoreKeys is what a FakeTerminal returns once its script has run out.
+var ErrNoMoreKeys = errors.New("no more scripted keys")
+
+// FakeTerminal plays back a script of key presses and keeps everything drawn to it,
+// for driving an Interaction without a real terminal.
+type FakeTerminal struct {
+	Keys     []Key
+	Width    int
+	Height   int
+	Output   bytes.Buffer
+	Acquired bool // Between Acquire and Release
+}
+
+func NewFakeTerminal(keys ...Key) *FakeTerminal {
+	return &FakeTerminal{Keys: keys, Width: defaultWidth, Height: defaultHeight}
+}
+
+func (f *FakeTerminal) Write(p []byte) (int, error) {
+	return f.Output.Write(p)
+}
+
+func (f *FakeTerminal) Acquire() error {
+	f.Acquired = true
+	return nil
+}
+
+func (f *FakeTerminal) Release() error {
+	f.Acquired = false
+	return nil
+}
+
+func (f *FakeTerminal) ReadKey() (Key, error) {
+	if len(f.Keys) == 0 {
+		return Key{}, ErrNoMoreKeys
+	}
+	key := f.Keys[0]
+	f.Keys = f.Keys[1:]
+	return key, nil
+}
+
+func (f *FakeTerminal) Size() (int, int) {
+	return f.Width, f.Height
+}
+
+// Frames splits everything drawn so far into the frames that were rendered, oldest first.
+func (f *FakeTerminal) Frames() []string {
+	frames := strings.Split(f.Output.String(), frameStart)
+	for j, frame := range frames {
+		frame, _, _ = strings.Cut(frame, clearBelow)
+		frames[j] = strings.ReplaceAll(frame, clearLine+"\r\n", "\n")
+	}
+	return frames[1:]
+}
+
+// LastFrame is the most recently rendered frame, empty if nothing was rendered.
+func (f *FakeTerminal) LastFrame() string {
+	frames := f.Frames()
+	if len(frames) == 0 {
+		return ""
+	}
+	return frames[len(frames)-1]
+}
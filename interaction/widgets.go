@@ -0,0 +1,328 @@
+package interaction
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/buger/goterm"
+)
+
+// Widget is one piece of a prompt, e.g. its list of options or a search bar. Every widget is drawn
+// on each render, the focused one also gets the first look at key presses.
+type Widget interface {
+	// Render draws the widget in lines no wider than width.
+	Render(w io.Writer, i *Interaction, focused bool, width int)
+	// HandleKey reports whether the widget used the key, keys it passes on are tried as global keys.
+	HandleKey(i *Interaction, key Key) bool
+	Focusable() bool
+	// Hints describes the widget's keys in the interaction's keymap, shown under the title while it has the focus.
+	Hints(i *Interaction) string
+}
+
+// defaultPageSize is how many options a list shows at once until it is laid out for a terminal.
+const defaultPageSize = 10
+
+// List is a paginated list of options with a cursor. Pages are as long as the rows the terminal has left
+// once the prompt's other widgets are drawn, so they change when the terminal is resized.
+type List struct {
+	Options     []*Option
+	Filtered    []*Option         // When not nil, shown in place of Options, e.g. search results
+	IsPaginated bool              // Whether the list is expected to need more than one page, long lists page either way
+	Cursor      int               // Index in the visible options, the page follows it
+	PageSize    int               // Set on every render, from the size of the terminal
+	Labels      map[Action]string // Replace the default labels in the key hints, e.g. what selecting does in this list
+}
+
+func NewList(isPaginated bool) *List {
+	return &List{
+		Options:     make([]*Option, 0),
+		IsPaginated: isPaginated,
+		PageSize:    defaultPageSize,
+	}
+}
+
+func (l *List) AddOption(title string, description string, packet any) *Option {
+	o := &Option{
+		Title:       title,
+		Description: description,
+		Packet:      packet,
+	}
+	l.Options = append(l.Options, o)
+	return o
+}
+
+// Clear removes every option.
+func (l *List) Clear() {
+	l.Options = make([]*Option, 0)
+	l.Filtered = nil
+	l.Reset()
+}
+
+// Filter shows only the given options, in their order, until it is called again with nil.
+func (l *List) Filter(options []*Option) {
+	l.Filtered = options
+	l.Reset()
+}
+
+// Reset moves back to the first option.
+func (l *List) Reset() {
+	l.Cursor = 0
+}
+
+func (l *List) visible() []*Option {
+	if l.Filtered != nil {
+		return l.Filtered
+	}
+	return l.Options
+}
+
+func (l *List) pageSize() int {
+	return max(1, l.PageSize)
+}
+
+// Pages is how many pages the list has, at least one even when empty.
+func (l *List) Pages() int {
+	return max(1, (len(l.visible())+l.pageSize()-1)/l.pageSize())
+}
+
+// PageIdx is the page the cursor is on.
+func (l *List) PageIdx() int {
+	return l.Cursor / l.pageSize()
+}
+
+// Page is the options on the current page.
+func (l *List) Page() []*Option {
+	options := l.visible()
+	start := min(l.PageIdx()*l.pageSize(), len(options))
+	end := min(start+l.pageSize(), len(options))
+	return options[start:end]
+}
+
+// Current is the option under the cursor, nil if the list is empty.
+func (l *List) Current() *Option {
+	options := l.visible()
+	if l.Cursor < 0 || l.Cursor >= len(options) {
+		return nil
+	}
+	return options[l.Cursor]
+}
+
+func (l *List) HandleKey(i *Interaction, key Key) bool {
+	switch i.Keymap.Action(key) {
+	case ActionNextPage:
+		if l.PageIdx()+1 < l.Pages() {
+			l.Cursor = (l.PageIdx() + 1) * l.pageSize()
+		}
+	case ActionPrevPage:
+		if l.PageIdx()-1 >= 0 {
+			l.Cursor = (l.PageIdx() - 1) * l.pageSize()
+		}
+	case ActionUp:
+		if l.Cursor-1 >= 0 {
+			l.Cursor -= 1
+		}
+	case ActionDown:
+		if l.Cursor+1 < len(l.visible()) {
+			l.Cursor += 1
+		}
+	case ActionFirst:
+		l.Reset()
+	case ActionLast:
+		l.Cursor = max(0, len(l.visible())-1)
+	case ActionSelect:
+		i.Select(l.Current())
+	case ActionToggle:
+		i.Toggle(l.Current())
+	default:
+		return false
+	}
+	return true
+}
+
+func (l *List) Focusable() bool {
+	return true
+}
+
+// Hints only mentions the keys that do something here: paging in paginated lists, toggling when there
+// are options to select, going back from a prompt with a parent and searching from one with a search bar.
+func (l *List) Hints(i *Interaction) string {
+	p := i.getCurrentPrompt()
+	hints := []Hint{}
+	if p.input() != nil {
+		hints = append(hints, Hint{ActionSearch, "Select search"})
+	}
+	if l.IsPaginated {
+		hints = append(hints, Hint{ActionNextPage, "Next page"}, Hint{ActionPrevPage, "Last page"})
+	}
+	hints = append(hints, Hint{ActionSelect, "Select"})
+	for _, o := range l.Options {
+		if o.Selectable {
+			hints = append(hints, Hint{ActionToggle, "Toggle"}, Hint{ActionBatch, "Install selected"})
+			break
+		}
+	}
+	if p.ParentIdx != p.Idx {
+		hints = append(hints, Hint{ActionBack, "Back"})
+	}
+	hints = append(hints, Hint{ActionExit, "Exit"})
+
+	for j, h := range hints {
+		if label, ok := l.Labels[h.Action]; ok {
+			hints[j].Label = label
+		}
+	}
+	return i.Keymap.Hints(hints...)
+}
+
+// Fit sizes pages to the rows there are to draw the list in, leaving one for the page number if it needs it.
+func (l *List) Fit(rows int) {
+	l.PageSize = max(1, rows)
+	if len(l.visible()) > l.PageSize {
+		l.PageSize = max(1, rows-1)
+	}
+}
+
+func (l *List) Render(w io.Writer, i *Interaction, focused bool, width int) {
+	current := l.Cursor - l.PageIdx()*l.pageSize()
+
+	for j, v := range l.Page() {
+		var line string
+		switch j == current && focused {
+		case true:
+			line = goterm.Color(goterm.Bold(">  "), goterm.YELLOW) +
+				goterm.Color(goterm.Bold(checkbox(v)), goterm.YELLOW) + highlightTitle(v, true) +
+				goterm.Bold(" ("+v.Description+")")
+		case false:
+			line = "  " + checkbox(v) + highlightTitle(v, false) + " (" + v.Description + ")"
+		}
+		fmt.Fprintln(w, truncate(line, width))
+	}
+
+	if l.Pages() > 1 {
+		fmt.Fprintln(w, truncate(goterm.Color(fmt.Sprintf("Page %d of %d", l.PageIdx()+1, l.Pages()), goterm.BLUE), width))
+	}
+}
+
+// TextInput is a single line of text, e.g. a search bar.
+type TextInput struct {
+	Label    string
+	Value    string
+	OnChange func(value string) // Called after every edit
+}
+
+// HandleKey takes every typed character, whatever the keymap binds it to. Moving down or selecting
+// hands the focus over to the prompt's list.
+func (t *TextInput) HandleKey(i *Interaction, key Key) bool {
+	switch {
+	case key.Code == KeyRune && !key.Alt:
+		t.Value += string(key.Rune)
+	case key.Code == KeyBackspace:
+		if runes := []rune(t.Value); len(runes) > 0 {
+			t.Value = string(runes[:len(runes)-1])
+		}
+	case i.Keymap.Action(key) == ActionDown || i.Keymap.Action(key) == ActionSelect:
+		p := i.getCurrentPrompt()
+		p.FocusOn(p.List)
+		return true
+	default:
+		return false
+	}
+
+	if t.OnChange != nil {
+		t.OnChange(t.Value)
+	}
+	return true
+}
+
+func (t *TextInput) Focusable() bool {
+	return true
+}
+
+func (t *TextInput) Hints(i *Interaction) string {
+	return i.Keymap.TypingHints(Hint{ActionDown, "Select results"}, Hint{ActionFocus, "Switch focus"}, Hint{ActionExit, "Exit"})
+}
+
+func (t *TextInput) Render(w io.Writer, i *Interaction, focused bool, width int) {
+	prefix := t.Label + " >>"
+	if focused {
+		prefix = "> " + t.Label + ": "
+	}
+
+	// Keep the end of a long value in view, that is where the typing happens
+	value := t.Value
+	if room := width - 2 - visibleWidth(prefix); visibleWidth(value) > room {
+		value = ellipsis + lastCells(value, room-1)
+	}
+
+	display := goterm.Bold(prefix + value)
+	if focused {
+		display = goterm.Color(display, goterm.CYAN)
+	}
+
+	rule := strings.Repeat("-", max(0, width))
+	fmt.Fprintln(w, rule)
+	fmt.Fprintln(w, truncate(" "+display, width))
+	fmt.Fprintln(w, rule)
+}
+
+// detailLines is how many lines of description a DetailPane shows at most.
+const detailLines = 3
+
+// DetailPane shows the description of the option under a list's cursor, wrapped rather than cut off.
+type DetailPane struct {
+	Source *List
+}
+
+func (d *DetailPane) HandleKey(i *Interaction, key Key) bool {
+	return false
+}
+
+func (d *DetailPane) Focusable() bool {
+	return false
+}
+
+func (d *DetailPane) Hints(i *Interaction) string {
+	return ""
+}
+
+func (d *DetailPane) Render(w io.Writer, i *Interaction, focused bool, width int) {
+	o := d.Source.Current()
+	if o == nil || o.Description == "" {
+		return
+	}
+	fmt.Fprintf(w, "\n%s\n", truncate(goterm.Bold(o.Title), width))
+	for _, line := range clip(wrap(o.Description, width), detailLines) {
+		fmt.Fprintln(w, line)
+	}
+}
+
+// StatusBar shows what the last callback or batch action reported.
+type StatusBar struct{}
+
+func (s *StatusBar) HandleKey(i *Interaction, key Key) bool {
+	return false
+}
+
+func (s *StatusBar) Focusable() bool {
+	return false
+}
+
+func (s *StatusBar) Hints(i *Interaction) string {
+	return ""
+}
+
+func (s *StatusBar) Render(w io.Writer, i *Interaction, focused bool, width int) {
+	if i.Message != "" {
+		fmt.Fprint(w, "\n")
+		for _, line := range wrap(i.Message, width) {
+			fmt.Fprintln(w, line)
+		}
+	}
+	if i.MessageErr != nil {
+		fmt.Fprint(w, "\n")
+		for _, line := range wrap(i.MessageErr.Error(), width) {
+			fmt.Fprintln(w, goterm.Color(line, goterm.RED))
+		}
+	}
+}
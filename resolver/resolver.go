@@ -0,0 +1,255 @@
+// Package resolver works out the Go import path behind a catalog link, so it can be handed to go get.
+//
+// Links on well known code hosts are mapped by rules. Everything else goes through the same
+// go-import meta tag discovery the go command uses for vanity import paths.
+package resolver
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ErrNotInstallable means the link does not lead to anything go get could install, like a website or docs page.
+var ErrNotInstallable = errors.New("not an installable go package")
+
+type Resolver struct {
+	Client *http.Client // Used for go-import discovery, defaults to http.DefaultClient
+	Majors MajorFinder  // When set, asked whether a repository on a known host has moved on to a /vN module
+
+	mu    sync.Mutex
+	cache map[string]result
+}
+
+// MajorFinder finds the newest major version of a module, e.g. github.com/jackc/pgx -> github.com/jackc/pgx/v5.
+// Links to a repository never say which major version is current, while v2 and up have their own module path.
+type MajorFinder interface {
+	NewestMajor(path string) (string, error)
+}
+
+type result struct {
+	path string
+	err  error
+}
+
+func New() *Resolver {
+	return &Resolver{
+		cache: make(map[string]result),
+	}
+}
+
+// Resolve maps a catalog link to an import path, e.g.
+// https://github.com/spf13/cobra/tree/main/doc -> github.com/spf13/cobra/doc
+// or, for a repository whose newest module is a v2 or later, https://github.com/jackc/pgx -> github.com/jackc/pgx/v5
+// Results are cached, so asking about the same link twice only hits the network once.
+func (r *Resolver) Resolve(link string) (string, error) {
+	r.mu.Lock()
+	cached, ok := r.cache[link]
+	r.mu.Unlock()
+	if ok {
+		return cached.path, cached.err
+	}
+
+	path, root, known, err := static(link)
+	if err == nil && !known {
+		path, err = r.discover(link, path)
+	}
+	if err == nil && root != "" && r.Majors != nil {
+		path = r.newestMajor(path, root)
+	}
+
+	r.mu.Lock()
+	if r.cache == nil {
+		r.cache = make(map[string]result)
+	}
+	r.cache[link] = result{path: path, err: err}
+	r.mu.Unlock()
+
+	return path, err
+}
+
+// newestMajor moves path, inside the repository at root, over to the newest major version module of it.
+// Paths that already name a major version are left alone, and so is everything when the lookup fails,
+// since go get can still make something of the path as it is.
+func (r *Resolver) newestMajor(path string, root string) string {
+	rest := strings.TrimPrefix(path, root)
+	if first, _, _ := strings.Cut(strings.TrimPrefix(rest, "/"), "/"); isMajor(first) {
+		return path
+	}
+	newest, err := r.Majors.NewestMajor(root)
+	if err != nil || newest == "" {
+		return path
+	}
+	return newest + rest
+}
+
+// isMajor reports whether a path element is a major version suffix, v2 or later.
+func isMajor(elem string) bool {
+	n, err := strconv.Atoi(strings.TrimPrefix(elem, "v"))
+	return strings.HasPrefix(elem, "v") && err == nil && n >= 2
+}
+
+// Static applies the known host rules without touching the network.
+// When known is false path is only a guess, and Resolve would confirm it with go-import discovery.
+// Links to repositories carry no major version, so path is always the v0/v1 module; Resolve finds newer ones.
+func Static(link string) (path string, known bool, err error) {
+	path, _, known, err = static(link)
+	return path, known, err
+}
+
+// static is Static, also returning the root of the repository path is in on hosts where one can be told
+// from the link, empty elsewhere.
+func static(link string) (path string, root string, known bool, err error) {
+	u, err := url.Parse(strings.TrimSpace(link))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", "", true, fmt.Errorf("%w: %q is not a web link", ErrNotInstallable, link)
+	}
+
+	host := strings.TrimPrefix(strings.ToLower(u.Host), "www.")
+	segments := []string{}
+	for _, s := range strings.Split(u.Path, "/") {
+		if s != "" {
+			segments = append(segments, s)
+		}
+	}
+
+	switch host {
+	case "github.com", "bitbucket.org", "codeberg.org", "gitea.com":
+		// host/owner/repo, plus a subdirectory when linking into the tree
+		// e.g. github.com/owner/repo/tree/master/cmd/tool or bitbucket.org/owner/repo/src/main/pkg
+		if len(segments) < 2 {
+			return "", "", true, fmt.Errorf("%w: %s is not a repository", ErrNotInstallable, link)
+		}
+		root = host + "/" + segments[0] + "/" + strings.TrimSuffix(segments[1], ".git")
+		path = root
+		if len(segments) > 4 && (segments[2] == "tree" || segments[2] == "src" || segments[2] == "blob") {
+			sub := segments[4:]
+			// Links to a file mean the package it is in
+			if segments[2] == "blob" || (segments[2] == "src" && strings.Contains(sub[len(sub)-1], ".")) {
+				sub = sub[:len(sub)-1]
+			}
+			if len(sub) > 0 {
+				path += "/" + strings.Join(sub, "/")
+			}
+		}
+		return path, root, true, nil
+	case "gitlab.com":
+		// GitLab allows nested groups, everything before the /-/ separator is the project
+		project, sub := segments, []string{}
+		for i, s := range segments {
+			if s == "-" {
+				project = segments[:i]
+				if len(segments) > i+3 && segments[i+1] == "tree" {
+					sub = segments[i+3:]
+				}
+				break
+			}
+		}
+		if len(project) < 2 {
+			return "", "", true, fmt.Errorf("%w: %s is not a repository", ErrNotInstallable, link)
+		}
+		root = host + "/" + strings.TrimSuffix(strings.Join(project, "/"), ".git")
+		return strings.Join(append([]string{root}, sub...), "/"), root, true, nil
+	case "pkg.go.dev", "godoc.org":
+		// Docs links carry the import path itself, minus any @version
+		if len(segments) == 0 || segments[0] == "search" || !strings.Contains(segments[0], ".") {
+			return "", "", true, fmt.Errorf("%w: %s is not a package page", ErrNotInstallable, link)
+		}
+		for i, s := range segments {
+			segments[i], _, _ = strings.Cut(s, "@")
+		}
+		return strings.Join(segments, "/"), "", true, nil
+	case "go.googlesource.com":
+		if len(segments) == 0 {
+			return "", "", true, fmt.Errorf("%w: %s is not a repository", ErrNotInstallable, link)
+		}
+		return "golang.org/x/" + strings.Join(segments, "/"), "", true, nil
+	case "golang.org", "gopkg.in":
+		if len(segments) == 0 {
+			return "", "", true, fmt.Errorf("%w: %s is not a package", ErrNotInstallable, link)
+		}
+		return host + "/" + strings.Join(segments, "/"), "", true, nil
+	}
+
+	// Anything else could be a vanity import path, or just a website
+	if len(segments) == 0 {
+		return host, "", false, nil
+	}
+	return host + "/" + strings.Join(segments, "/"), "", false, nil
+}
+
+// discover asks the server behind link for go-import meta tags, the same way `go get` does for vanity paths.
+func (r *Resolver) discover(link string, guess string) (string, error) {
+	client := r.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	u, err := url.Parse(link)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrNotInstallable, err)
+	}
+	q := u.Query()
+	q.Set("go-get", "1")
+	u.RawQuery = q.Encode()
+	u.Fragment = ""
+
+	resp, err := client.Get(u.String())
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	// The meta tags live in the <head>, no need to read a whole docs site
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", err
+	}
+
+	prefixes := goImports(body)
+	for _, prefix := range prefixes {
+		if guess == prefix || strings.HasPrefix(guess, prefix+"/") {
+			return guess, nil
+		}
+	}
+	// A homepage pointing at a single module is close enough, e.g. https://gorm.io -> gorm.io/gorm
+	if len(prefixes) == 1 {
+		return prefixes[0], nil
+	}
+
+	return "", fmt.Errorf("%w: no go-import meta tag found at %s", ErrNotInstallable, link)
+}
+
+var (
+	metaTag  = regexp.MustCompile(`(?is)<meta\s[^>]*>`)
+	metaAttr = regexp.MustCompile(`(?is)(name|content)\s*=\s*("[^"]*"|'[^']*')`)
+)
+
+// goImports pulls the import prefixes out of every <meta name="go-import" content="prefix vcs repo"> tag.
+func goImports(page []byte) []string {
+	prefixes := []string{}
+	for _, tag := range metaTag.FindAll(page, -1) {
+		var name, content string
+		for _, attr := range metaAttr.FindAllSubmatch(tag, -1) {
+			value := strings.Trim(string(attr[2]), `"'`)
+			switch strings.ToLower(string(attr[1])) {
+			case "name":
+				name = value
+			case "content":
+				content = value
+			}
+		}
+
+		fields := strings.Fields(content)
+		if name == "go-import" && len(fields) == 3 {
+			prefixes = append(prefixes, fields[0])
+		}
+	}
+	return prefixes
+}
@@ -0,0 +1,201 @@
+package resolver
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestStatic(t *testing.T) {
+	tests := []struct {
+		link  string
+		path  string
+		known bool
+		err   error
+	}{
+		{"https://github.com/spf13/cobra", "github.com/spf13/cobra", true, nil},
+		{"https://www.github.com/spf13/cobra.git", "github.com/spf13/cobra", true, nil},
+		{"https://github.com/spf13/cobra/tree/main/doc", "github.com/spf13/cobra/doc", true, nil},
+		{"https://github.com/spf13/cobra/tree/main", "github.com/spf13/cobra", true, nil},
+		{"https://github.com/spf13/cobra/blob/main/doc/md_docs.go", "github.com/spf13/cobra/doc", true, nil},
+		{"https://github.com/spf13/cobra/blob/main/README.md", "github.com/spf13/cobra", true, nil},
+		{"https://github.com/spf13/cobra#readme", "github.com/spf13/cobra", true, nil},
+		{"https://bitbucket.org/owner/repo/src/main/pkg", "bitbucket.org/owner/repo/pkg", true, nil},
+		{"https://bitbucket.org/owner/repo/src/main/pkg/file.go", "bitbucket.org/owner/repo/pkg", true, nil},
+		{"https://codeberg.org/owner/repo", "codeberg.org/owner/repo", true, nil},
+		{"https://github.com/spf13", "", true, ErrNotInstallable},
+		{"https://gitlab.com/group/project", "gitlab.com/group/project", true, nil},
+		{"https://gitlab.com/group/subgroup/project/-/tree/main", "gitlab.com/group/subgroup/project", true, nil},
+		{"https://gitlab.com/group/project/-/tree/main/pkg/sub", "gitlab.com/group/project/pkg/sub", true, nil},
+		{"https://gitlab.com/group/project/-/blob/main/README.md", "gitlab.com/group/project", true, nil},
+		{"https://gitlab.com/group", "", true, ErrNotInstallable},
+		{"https://pkg.go.dev/github.com/spf13/cobra", "github.com/spf13/cobra", true, nil},
+		{"https://pkg.go.dev/github.com/spf13/cobra@v1.8.0", "github.com/spf13/cobra", true, nil},
+		{"https://pkg.go.dev/golang.org/x/mod@v0.20.0/semver", "golang.org/x/mod/semver", true, nil},
+		{"https://pkg.go.dev/search?q=cobra", "", true, ErrNotInstallable},
+		{"https://pkg.go.dev/fmt", "", true, ErrNotInstallable},
+		{"https://godoc.org/github.com/pkg/errors", "github.com/pkg/errors", true, nil},
+		{"https://go.googlesource.com/tools", "golang.org/x/tools", true, nil},
+		{"https://golang.org/x/net/html", "golang.org/x/net/html", true, nil},
+		{"https://gopkg.in/yaml.v3", "gopkg.in/yaml.v3", true, nil},
+		{"https://gorm.io", "gorm.io", false, nil},
+		{"https://gorm.io/docs/", "gorm.io/docs", false, nil},
+		{"mailto:someone@example.com", "", true, ErrNotInstallable},
+		{"ftp://example.com/pkg", "", true, ErrNotInstallable},
+		{"#actor-model", "", true, ErrNotInstallable},
+		{"github.com/spf13/cobra", "", true, ErrNotInstallable},
+	}
+	for _, tt := range tests {
+		path, known, err := Static(tt.link)
+		if !errors.Is(err, tt.err) || (tt.err == nil && err != nil) {
+			t.Errorf("Static(%q) error = %v, want %v", tt.link, err, tt.err)
+			continue
+		}
+		if path != tt.path || known != tt.known {
+			t.Errorf("Static(%q) = %q, %t, want %q, %t", tt.link, path, known, tt.path, tt.known)
+		}
+	}
+}
+
+func TestGoImports(t *testing.T) {
+	page := `<html><head>
+<meta name="go-import" content="example.com/mod git https://github.com/example/mod">
+<META CONTENT='example.com/other git https://github.com/example/other' NAME='go-import'>
+<meta name="go-source" content="example.com/mod https://github.com/example/mod x y">
+<meta name="go-import" content="broken">
+<meta name="description" content="example.com/nope git https://example.com">
+</head><body>not a <meta name="go-import" content="example.com/late mod https://proxy.example.com"></body></html>`
+
+	got := goImports([]byte(page))
+	want := []string{"example.com/mod", "example.com/other", "example.com/late"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("goImports = %q, want %q", got, want)
+	}
+}
+
+// vanityServer answers go-get=1 requests with a go-import tag for every prefix, relative to its own host.
+func vanityServer(t *testing.T, prefixes ...string) (*httptest.Server, *[]string) {
+	t.Helper()
+	var queries []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.RawQuery)
+		if r.URL.Query().Get("go-get") != "1" {
+			fmt.Fprint(w, "<html><body>a website</body></html>")
+			return
+		}
+		fmt.Fprint(w, "<html><head>")
+		for _, prefix := range prefixes {
+			fmt.Fprintf(w, `<meta name="go-import" content="%s%s git https://example.com/repo">`, r.Host, prefix)
+		}
+		fmt.Fprint(w, "</head></html>")
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &queries
+}
+
+func TestResolveDiscover(t *testing.T) {
+	srv, queries := vanityServer(t, "/mod")
+	host := strings.TrimPrefix(srv.URL, "http://")
+
+	tests := []struct {
+		link string
+		path string
+	}{
+		{srv.URL + "/mod/sub", host + "/mod/sub"}, // Under the prefix, the guess stands
+		{srv.URL + "/mod?tab=doc", host + "/mod"}, // Existing queries are kept
+		{srv.URL + "/docs/intro", host + "/mod"},  // A single module is close enough
+		{srv.URL + "/mod/sub#section", host + "/mod/sub"},
+	}
+	for _, tt := range tests {
+		r := New()
+		r.Client = srv.Client()
+		path, err := r.Resolve(tt.link)
+		if err != nil || path != tt.path {
+			t.Errorf("Resolve(%q) = %q, %v, want %q", tt.link, path, err, tt.path)
+		}
+	}
+	if q := (*queries)[1]; !strings.Contains(q, "go-get=1") || !strings.Contains(q, "tab=doc") {
+		t.Errorf("query sent = %q, want go-get=1 added to the link's own", q)
+	}
+}
+
+func TestResolveDiscoverNoTag(t *testing.T) {
+	srv, _ := vanityServer(t, "/a", "/b")
+	r := New()
+	r.Client = srv.Client()
+
+	if _, err := r.Resolve(srv.URL + "/c"); !errors.Is(err, ErrNotInstallable) {
+		t.Errorf("got %v, want ErrNotInstallable when no prefix matches and there are several", err)
+	}
+
+	none, _ := vanityServer(t)
+	r.Client = none.Client()
+	if _, err := r.Resolve(none.URL + "/website"); !errors.Is(err, ErrNotInstallable) {
+		t.Errorf("got %v, want ErrNotInstallable for a page without go-import tags", err)
+	}
+}
+
+func TestResolveCaches(t *testing.T) {
+	srv, queries := vanityServer(t, "/mod")
+	r := New()
+	r.Client = srv.Client()
+
+	for j := 0; j < 3; j++ {
+		if _, err := r.Resolve(srv.URL + "/mod"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(*queries) != 1 {
+		t.Errorf("%d requests for the same link, want 1", len(*queries))
+	}
+}
+
+// majors is a MajorFinder over a fixed set of answers, anything else having no newer major.
+type majors map[string]string
+
+func (m majors) NewestMajor(path string) (string, error) {
+	if newest, ok := m[path]; ok {
+		return newest, nil
+	}
+	if path == "github.com/broken/proxy" {
+		return "", errors.New("proxy unreachable")
+	}
+	return path, nil
+}
+
+func TestResolveNewestMajor(t *testing.T) {
+	finder := majors{
+		"github.com/jackc/pgx":         "github.com/jackc/pgx/v5",
+		"gitlab.com/group/sub/project": "gitlab.com/group/sub/project/v3",
+		"bitbucket.org/owner/repo":     "bitbucket.org/owner/repo/v2",
+	}
+	tests := []struct {
+		link string
+		path string
+	}{
+		{"https://github.com/jackc/pgx", "github.com/jackc/pgx/v5"},
+		{"https://github.com/jackc/pgx/tree/master/pgxpool", "github.com/jackc/pgx/v5/pgxpool"},
+		{"https://github.com/spf13/cobra", "github.com/spf13/cobra"},
+		{"https://gitlab.com/group/sub/project/-/tree/main/pkg", "gitlab.com/group/sub/project/v3/pkg"},
+		{"https://bitbucket.org/owner/repo/src/main/pkg/file.go", "bitbucket.org/owner/repo/v2/pkg"},
+		// A link into a major version's directory already names the module
+		{"https://github.com/jackc/pgx/tree/master/v4", "github.com/jackc/pgx/v4"},
+		// Docs links carry the module path as it is, version suffix and all
+		{"https://pkg.go.dev/github.com/jackc/pgx/v5", "github.com/jackc/pgx/v5"},
+		{"https://pkg.go.dev/github.com/jackc/pgx", "github.com/jackc/pgx"},
+		// The lookup failing leaves the path for go get to make what it can of
+		{"https://github.com/broken/proxy", "github.com/broken/proxy"},
+	}
+	for _, tt := range tests {
+		r := New()
+		r.Majors = finder
+		path, err := r.Resolve(tt.link)
+		if err != nil || path != tt.path {
+			t.Errorf("Resolve(%q) = %q, %v, want %q", tt.link, path, err, tt.path)
+		}
+	}
+}
@@ -0,0 +1,13 @@
+package store
+
+import "errors"
+
+// Every error out of this package wraps one of these so callers can tell what went wrong with errors.Is.
+var (
+	// ErrFetch means the catalog source could not be reached or read, most likely because we are offline.
+	ErrFetch = errors.New("could not fetch the catalog")
+	// ErrParse means the source was fetched but did not look like a package list.
+	ErrParse = errors.New("could not parse the catalog")
+	// ErrCorruptStore means the catalog file on disk could not be read back.
+	ErrCorruptStore = errors.New("catalog file is corrupt")
+)
@@ -0,0 +1,207 @@
+package store
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+// section is a heading in the markdown along with everything listed under it.
+// Together they form the category -> subcategory hierarchy of the list.
+type section struct {
+	level       int
+	name        string
+	description string
+	entries     []Entry
+	parent      *section
+	children    []*section
+}
+
+// ParseMD parses an awesome-go style markdown list into a Store.
+// Every ## or deeper heading is a category, its italic paragraph the description, and every
+// "- [name](link) - description" list item under it an entry.
+// It fails with ErrParse if the markdown has no package links in it.
+func ParseMD(body []byte) (Store, error) {
+	root := parseSections(body)
+
+	store := Store{
+		Entries:    []Entry{},
+		Categories: []Category{},
+	}
+
+	// Categories are stored flat, Parent and Children link them back into a tree.
+	var flatten func(s *section)
+	flatten = func(s *section) {
+		if s != root && (len(s.entries) > 0 || len(s.children) > 0) {
+			c := Category{
+				Name:        s.name,
+				Description: s.description,
+				Children:    []string{},
+				Entries:     s.entries,
+			}
+			if s.parent != root {
+				c.Parent = s.parent.name
+			}
+			for _, child := range s.children {
+				if len(child.entries) > 0 || len(child.children) > 0 {
+					c.Children = append(c.Children, child.name)
+				}
+			}
+			if c.Entries == nil {
+				c.Entries = []Entry{}
+			}
+
+			store.Categories = append(store.Categories, c)
+			store.Entries = append(store.Entries, s.entries...)
+		}
+		for _, child := range s.children {
+			flatten(child)
+		}
+	}
+	flatten(root)
+
+	if len(store.Entries) == 0 {
+		return store, fmt.Errorf("%w: no package links found", ErrParse)
+	}
+	return store, nil
+}
+
+// parseSections walks the markdown AST and builds the heading tree.
+// The returned root stands in for the document title and never holds entries.
+func parseSections(body []byte) *section {
+	doc := goldmark.New().Parser().Parse(text.NewReader(body))
+
+	root := &section{level: 1}
+	current := root
+
+	for n := doc.FirstChild(); n != nil; n = n.NextSibling() {
+		switch n := n.(type) {
+		case *ast.Heading:
+			// The # title is the list itself, and other # headings like Resources aren't categories. Either
+			// way what comes under them until the next ## belongs to no category.
+			if n.Level < 2 {
+				current = root
+				continue
+			}
+
+			parent := current
+			for parent != root && parent.level >= n.Level {
+				parent = parent.parent
+			}
+
+			s := &section{
+				level:  n.Level,
+				name:   strings.TrimSpace(plainText(n, body)),
+				parent: parent,
+			}
+			parent.children = append(parent.children, s)
+			current = s
+		case *ast.Paragraph:
+			// A category description is the _italic_ paragraph right under its heading
+			if current == root || current.description != "" || len(current.entries) > 0 {
+				continue
+			}
+			if n.ChildCount() == 1 && n.FirstChild().Kind() == ast.KindEmphasis {
+				current.description = strings.TrimRight(strings.TrimSpace(plainText(n, body)), ".")
+			}
+		case *ast.List:
+			// Anything before the first heading is badges and sponsors
+			if current == root {
+				continue
+			}
+			current.entries = append(current.entries, listEntries(n, body, current.name)...)
+		}
+	}
+
+	return root
+}
+
+// listEntries pulls an Entry out of every list item that starts with a link, including nested lists.
+func listEntries(list *ast.List, source []byte, category string) []Entry {
+	entries := []Entry{}
+
+	for item := list.FirstChild(); item != nil; item = item.NextSibling() {
+		for block := item.FirstChild(); block != nil; block = block.NextSibling() {
+			if nested, ok := block.(*ast.List); ok {
+				entries = append(entries, listEntries(nested, source, category)...)
+				continue
+			}
+			if block != item.FirstChild() {
+				continue
+			}
+
+			link := firstLink(block, source)
+			if link == nil {
+				continue
+			}
+			// In-page anchors are the table of contents and "back to top" links
+			destination := string(link.Destination)
+			if strings.HasPrefix(destination, "#") {
+				continue
+			}
+
+			e := Entry{
+				Category: category,
+				Name:     strings.TrimSpace(plainText(link, source)),
+				Link:     destination,
+			}
+
+			var description strings.Builder
+			for rest := link.NextSibling(); rest != nil; rest = rest.NextSibling() {
+				description.WriteString(plainText(rest, source))
+			}
+			e.Description = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(description.String()), "-–—:"))
+
+			entries = append(entries, e)
+		}
+	}
+
+	return entries
+}
+
+// firstLink finds the link an entry starts with. Images (badges) don't count.
+func firstLink(block ast.Node, source []byte) *ast.Link {
+	for n := block.FirstChild(); n != nil; n = n.NextSibling() {
+		switch n := n.(type) {
+		case *ast.Link:
+			if n.FirstChild() != nil && n.FirstChild().Kind() == ast.KindImage {
+				return nil
+			}
+			return n
+		case *ast.Text:
+			// Leading whitespace is fine, anything else means this item isn't an entry
+			if strings.TrimSpace(string(n.Segment.Value(source))) != "" {
+				return nil
+			}
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+// plainText flattens a node into its text, dropping any formatting.
+func plainText(n ast.Node, source []byte) string {
+	var b strings.Builder
+	ast.Walk(n, func(c ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch c := c.(type) {
+		case *ast.Text:
+			b.Write(c.Segment.Value(source))
+			if c.SoftLineBreak() || c.HardLineBreak() {
+				b.WriteByte(' ')
+			}
+		case *ast.String:
+			b.Write(c.Value)
+		case *ast.AutoLink:
+			b.Write(c.URL(source))
+		}
+		return ast.WalkContinue, nil
+	})
+	return b.String()
+}
@@ -0,0 +1,53 @@
+package store
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata from the current output")
+
+// testdata/README.md is a trimmed snapshot of the awesome-go README: badges, the contents with its
+// anchors, nested categories, links with parentheses and "back to top" links are all kept as they are upstream.
+func TestParseMDGolden(t *testing.T) {
+	body, err := os.ReadFile(filepath.Join("testdata", "README.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := ParseMD(body)
+	if err != nil {
+		t.Fatalf("ParseMD: %v", err)
+	}
+
+	got, err := json.MarshalIndent(store, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got = append(got, '\n')
+
+	golden := filepath.Join("testdata", "README.golden.json")
+	if *update {
+		if err := os.WriteFile(golden, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("%v, run go test ./store -update to create it", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("ParseMD output differs from %s, run go test ./store -update if the change is intended\ngot:\n%s", golden, got)
+	}
+}
+
+func TestParseMDNoEntries(t *testing.T) {
+	_, err := ParseMD([]byte("# Nothing\n\nJust some text, [a link](#anchor) and no list.\n"))
+	if !errors.Is(err, ErrParse) {
+		t.Errorf("got %v, want ErrParse", err)
+	}
+}
@@ -0,0 +1,117 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// Changes counts what a refresh did to the catalog.
+type Changes struct {
+	EntriesAdded      int
+	EntriesRemoved    int
+	EntriesChanged    int
+	CategoriesAdded   int
+	CategoriesRemoved int
+	CategoriesChanged int
+}
+
+// Refresh re-fetches the catalog stored at path and writes it back if anything changed.
+// Sources that support it only download when the catalog has changed upstream. When nothing changed,
+// the returned bool is false and only the fetch time is written.
+func Refresh(path string, src Source) (Changes, bool, error) {
+	var old Store
+	file, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return Changes{}, false, fmt.Errorf("%w: %w", ErrCorruptStore, err)
+	}
+	if err == nil {
+		// A broken store gets replaced the same as a missing one.
+		json.Unmarshal(file, &old)
+	}
+
+	var fresh Store
+	if cs, ok := src.(ConditionalSource); ok {
+		fresh, err = cs.FetchIfModified(old.Meta)
+	} else {
+		fresh, err = src.Fetch()
+	}
+	// Sources without validators are fetched in full, an unchanged revision means the same thing as a 304
+	if err == nil && fresh.Meta.Source == old.Meta.Source && fresh.Meta.Revision == old.Meta.Revision {
+		err = ErrNotModified
+	}
+	if errors.Is(err, ErrNotModified) {
+		// Still worth noting that we checked, otherwise the catalog would look stale forever.
+		old.Meta.FetchedAt = time.Now()
+		return Changes{}, false, WriteFile(path, old)
+	}
+	if err != nil {
+		return Changes{}, false, err
+	}
+
+	fresh.Meta.FetchedAt = time.Now()
+	if err := WriteFile(path, fresh); err != nil {
+		return Changes{}, false, err
+	}
+	return Diff(old, fresh), true, nil
+}
+
+// Diff compares two stores. Entries are matched up by category and link, categories by name.
+func Diff(old Store, fresh Store) Changes {
+	var c Changes
+
+	entryKey := func(e Entry) string {
+		return e.Category + "\x00" + e.Link
+	}
+	oldEntries := make(map[string]Entry, len(old.Entries))
+	for _, e := range old.Entries {
+		oldEntries[entryKey(e)] = e
+	}
+	for _, e := range fresh.Entries {
+		prev, ok := oldEntries[entryKey(e)]
+		switch {
+		case !ok:
+			c.EntriesAdded += 1
+		case prev != e:
+			c.EntriesChanged += 1
+		}
+		delete(oldEntries, entryKey(e))
+	}
+	c.EntriesRemoved = len(oldEntries)
+
+	oldCategories := make(map[string]Category, len(old.Categories))
+	for _, v := range old.Categories {
+		oldCategories[v.Name] = v
+	}
+	for _, v := range fresh.Categories {
+		prev, ok := oldCategories[v.Name]
+		switch {
+		case !ok:
+			c.CategoriesAdded += 1
+		case !sameCategory(prev, v):
+			c.CategoriesChanged += 1
+		}
+		delete(oldCategories, v.Name)
+	}
+	c.CategoriesRemoved = len(oldCategories)
+
+	return c
+}
+
+func sameCategory(a Category, b Category) bool {
+	if a.Description != b.Description || a.Parent != b.Parent || len(a.Entries) != len(b.Entries) {
+		return false
+	}
+	if strings.Join(a.Children, "\x00") != strings.Join(b.Children, "\x00") {
+		return false
+	}
+	for i := range a.Entries {
+		if a.Entries[i] != b.Entries[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,78 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRefreshDiff(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, FileName)
+	readme := filepath.Join(dir, "README.md")
+
+	fixture, err := os.ReadFile(filepath.Join("testdata", "README.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(readme, fixture, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	src := FileSource{Path: readme}
+	if err := Init(path, src); err != nil {
+		t.Fatal(err)
+	}
+
+	edited := string(fixture)
+	for _, edit := range []struct{ old, new string }{
+		// A category's description changes, its entries don't
+		{"_Libraries for building actor-based programs._", "_Libraries for building programs out of actors._"},
+		// An entry is added to a category
+		{"- [Oto](https://github.com/hajimehoshi/oto)", "- [malgo](https://github.com/gen2brain/malgo) - Mini audio library.\n- [Oto](https://github.com/hajimehoshi/oto)"},
+		// An entry's description changes
+		{"Commander for modern Go CLI interactions.", "A library for creating powerful modern CLI applications."},
+		// An entry is removed
+		{"- [otto](https://github.com/robertkrimen/otto) - A JavaScript interpreter written in Go.\n", ""},
+		// A category is removed along with its entries, and another added with one
+		{"## E-Books\n\n- [An Introduction to Programming in Go](http://www.golang-book.com/)\n- [Go 101](https://go101.org) - A book focusing on Go syntax/semantics and all kinds of details.\n",
+			"## Zero Trust\n\n_Libraries for zero trust networking._\n\n- [openziti](https://github.com/openziti/sdk-golang) - Zero trust networking SDK.\n"},
+	} {
+		if !strings.Contains(edited, edit.old) {
+			t.Fatalf("fixture no longer contains %q", edit.old)
+		}
+		edited = strings.Replace(edited, edit.old, edit.new, 1)
+	}
+	if err := os.WriteFile(readme, []byte(edited), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	changes, updated, err := Refresh(path, src)
+	if err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if !updated {
+		t.Fatalf("a modified file should count as an update")
+	}
+	want := Changes{
+		EntriesAdded:      2, // malgo and openziti
+		EntriesRemoved:    3, // otto and both e-books
+		EntriesChanged:    1, // cobra
+		CategoriesAdded:   1, // Zero Trust
+		CategoriesRemoved: 1, // E-Books
+		CategoriesChanged: 4, // Actor Model, Audio and Music, Standard CLI and JavaScript
+	}
+	if changes != want {
+		t.Errorf("Changes = %+v, want %+v", changes, want)
+	}
+
+	var s Store
+	if err := ReadFile(path, &s); err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range s.Entries {
+		if e.Name == "otto" {
+			t.Errorf("the refreshed catalog was not written, otto is still in it")
+		}
+	}
+}
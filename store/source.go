@@ -0,0 +1,164 @@
+package store
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// AwesomeGoURL is where the default catalog comes from.
+const AwesomeGoURL = "https://raw.githubusercontent.com/avelino/awesome-go/main/README.md"
+
+// Source is anywhere a catalog can be loaded from.
+// Fetch should return the parsed entries and categories, ready to be written to the store file.
+type Source interface {
+	Fetch() (Store, error)
+	// Location is what Fetch records as Meta.Source, to tell which source a stored catalog came from.
+	Location() string
+}
+
+// ErrNotModified is returned by FetchIfModified when nothing changed since the last fetch.
+var ErrNotModified = errors.New("catalog not modified")
+
+// ConditionalSource is a Source that can skip the download when the catalog hasn't changed upstream.
+type ConditionalSource interface {
+	Source
+	FetchIfModified(prev Meta) (Store, error)
+}
+
+// URLSource downloads an awesome-go style markdown list from any url.
+type URLSource struct {
+	URL    string
+	Client *http.Client // Defaults to http.DefaultClient
+}
+
+// FileSource reads a catalog from disk.
+// Markdown files are parsed like the awesome-go README, .json files are expected to already be in the store format.
+type FileSource struct {
+	Path string
+}
+
+// AwesomeGo is the default source, the awesome-go README on github.
+func AwesomeGo() Source {
+	return URLSource{URL: AwesomeGoURL}
+}
+
+// NewSource picks a Source based on the location it is given, which can be a url or a file path.
+// An empty location means awesome-go.
+func NewSource(location string) Source {
+	if location == "" {
+		return AwesomeGo()
+	}
+	if strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://") {
+		return URLSource{URL: location}
+	}
+	return FileSource{Path: location}
+}
+
+func (s URLSource) Location() string {
+	return s.URL
+}
+
+func (s URLSource) Fetch() (Store, error) {
+	return s.FetchIfModified(Meta{})
+}
+
+// FetchIfModified sends the ETag and Last-Modified values from prev along, returning ErrNotModified if the server says nothing changed.
+func (s URLSource) FetchIfModified(prev Meta) (Store, error) {
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	req, err := http.NewRequest(http.MethodGet, s.URL, nil)
+	if err != nil {
+		return Store{}, fmt.Errorf("%w: %w", ErrFetch, err)
+	}
+	// Validators from another source mean nothing here
+	if prev.Source == s.URL {
+		if prev.ETag != "" {
+			req.Header.Set("If-None-Match", prev.ETag)
+		}
+		if prev.LastModified != "" {
+			req.Header.Set("If-Modified-Since", prev.LastModified)
+		}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return Store{}, fmt.Errorf("%w: %w", ErrFetch, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return Store{}, ErrNotModified
+	}
+	if resp.StatusCode != http.StatusOK {
+		return Store{}, fmt.Errorf("%w: GET %s: %s", ErrFetch, s.URL, resp.Status)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return Store{}, fmt.Errorf("%w: %w", ErrFetch, err)
+	}
+
+	store, err := ParseMD(body)
+	if err != nil {
+		return Store{}, fmt.Errorf("%s: %w", s.URL, err)
+	}
+	store.Meta = Meta{
+		Source:       s.Location(),
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		Revision:     resp.Header.Get("ETag"),
+	}
+	if store.Meta.Revision == "" {
+		store.Meta.Revision = revision(body)
+	}
+	return store, nil
+}
+
+// Location is the absolute path, so the same file given relative to another directory still matches.
+func (s FileSource) Location() string {
+	abs, err := filepath.Abs(s.Path)
+	if err != nil {
+		return s.Path
+	}
+	return abs
+}
+
+func (s FileSource) Fetch() (Store, error) {
+	body, err := os.ReadFile(s.Path)
+	if err != nil {
+		return Store{}, fmt.Errorf("%w: %w", ErrFetch, err)
+	}
+
+	if filepath.Ext(s.Path) == ".json" {
+		var store Store
+		if err := json.Unmarshal(body, &store); err != nil {
+			return Store{}, fmt.Errorf("%w: %s: %w", ErrParse, s.Path, err)
+		}
+		store.Meta = Meta{Source: s.Location(), Revision: revision(body)}
+		return store, nil
+	}
+
+	store, err := ParseMD(body)
+	if err != nil {
+		return Store{}, fmt.Errorf("%s: %w", s.Path, err)
+	}
+	store.Meta = Meta{Source: s.Location(), Revision: revision(body)}
+	return store, nil
+}
+
+// revision fingerprints a raw catalog for sources that don't hand out an ETag.
+func revision(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,136 @@
+package store
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const (
+	etag         = `"v1"`
+	lastModified = "Mon, 05 Oct 2026 10:00:00 GMT"
+)
+
+// catalogServer serves the README fixture with validators, answering 304 to requests that send them back.
+func catalogServer(t *testing.T, requests *[]*http.Request) *httptest.Server {
+	t.Helper()
+	body, err := os.ReadFile(filepath.Join("testdata", "README.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests = append(*requests, r)
+		if r.Header.Get("If-None-Match") == etag || r.Header.Get("If-Modified-Since") == lastModified {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Last-Modified", lastModified)
+		w.Write(body)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestURLSourceValidators(t *testing.T) {
+	var requests []*http.Request
+	srv := catalogServer(t, &requests)
+	src := URLSource{URL: srv.URL + "/README.md", Client: srv.Client()}
+	path := filepath.Join(t.TempDir(), FileName)
+
+	if err := Init(path, src); err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+	var first Store
+	if err := ReadFile(path, &first); err != nil {
+		t.Fatal(err)
+	}
+	if first.Meta.ETag != etag || first.Meta.LastModified != lastModified || first.Meta.Revision != etag {
+		t.Errorf("Meta = %+v, want the server's validators", first.Meta)
+	}
+
+	_, updated, err := Refresh(path, src)
+	if err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if updated {
+		t.Errorf("a 304 should not count as an update")
+	}
+	last := requests[len(requests)-1]
+	if last.Header.Get("If-None-Match") != etag || last.Header.Get("If-Modified-Since") != lastModified {
+		t.Errorf("validators not sent back, got headers %v", last.Header)
+	}
+
+	var second Store
+	if err := ReadFile(path, &second); err != nil {
+		t.Fatal(err)
+	}
+	if !second.Meta.FetchedAt.After(first.Meta.FetchedAt) || len(second.Entries) != len(first.Entries) {
+		t.Errorf("a 304 should keep the catalog and only bump the fetch time")
+	}
+}
+
+func TestURLSourceValidatorsFromAnotherSource(t *testing.T) {
+	var requests []*http.Request
+	srv := catalogServer(t, &requests)
+	src := URLSource{URL: srv.URL + "/README.md", Client: srv.Client()}
+
+	_, err := src.FetchIfModified(Meta{Source: srv.URL + "/other.md", ETag: etag, LastModified: lastModified})
+	if err != nil {
+		t.Fatalf("FetchIfModified: %v", err)
+	}
+	if h := requests[0].Header; h.Get("If-None-Match") != "" || h.Get("If-Modified-Since") != "" {
+		t.Errorf("validators from another source were sent: %v", h)
+	}
+}
+
+func TestURLSourceStatus(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+
+	_, err := URLSource{URL: srv.URL, Client: srv.Client()}.Fetch()
+	if !errors.Is(err, ErrFetch) {
+		t.Errorf("got %v, want ErrFetch", err)
+	}
+}
+
+func TestFileSourceRefreshUnchanged(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	src := FileSource{Path: filepath.Join("testdata", "README.md")}
+	if err := Init(path, src); err != nil {
+		t.Fatal(err)
+	}
+
+	before := time.Now()
+	_, updated, err := Refresh(path, src)
+	if err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if updated {
+		t.Errorf("refreshing an unchanged file should not count as an update")
+	}
+	var s Store
+	if err := ReadFile(path, &s); err != nil {
+		t.Fatal(err)
+	}
+	if s.Meta.FetchedAt.Before(before) {
+		t.Errorf("fetch time not bumped")
+	}
+}
+
+func TestFileSourceLocationIsAbsolute(t *testing.T) {
+	rel := FileSource{Path: filepath.Join("testdata", "README.md")}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	abs := FileSource{Path: filepath.Join(wd, "testdata", "README.md")}
+
+	if !filepath.IsAbs(rel.Location()) || rel.Location() != abs.Location() {
+		t.Errorf("Location() = %q and %q, want the same absolute path", rel.Location(), abs.Location())
+	}
+}
@@ -8,27 +8,91 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
-	"net/http"
 	"os"
-	"strings"
+	"path/filepath"
+	"time"
 )
 
-func Init() {
-	// TODO: We need to add functionality to basically refresh this list.
-	if _, err := os.Stat("store.json"); errors.Is(err, os.ErrNotExist) {
-		store := FetchAndParseMD()
-		jsonString, err := json.Marshal(store)
-		if err != nil {
-			panic(err)
-		}
-		os.WriteFile("store.json", jsonString, os.ModePerm)
+// FileName is what the catalog file is called inside the cache directory.
+const FileName = "store.json"
+
+// PathEnv can be set to keep the catalog somewhere other than the cache directory.
+const PathEnv = "GO_GET_CLI_STORE"
+
+// Path works out where the catalog lives. In order of preference that is the override
+// (from --store), $GO_GET_CLI_STORE, then go-get-cli/store.json under the user cache dir
+// ($XDG_CACHE_HOME on linux), so every project shares the same catalog.
+func Path(override string) (string, error) {
+	if override != "" {
+		return override, nil
+	}
+	if env := os.Getenv(PathEnv); env != "" {
+		return env, nil
+	}
+
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
 	}
+	return filepath.Join(cacheDir, "go-get-cli", FileName), nil
 }
 
-func ReadFile(target *Store) {
-	file, _ := os.ReadFile("store.json")
-	json.Unmarshal(file, &target)
+// Init writes a fresh catalog to path from the given source if there isn't one yet.
+// An existing catalog is kept whatever source it came from, see Refresh for replacing it.
+func Init(path string, src Source) error {
+	_, err := os.Stat(path)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("%w: %w", ErrCorruptStore, err)
+	}
+
+	store, err := src.Fetch()
+	if err != nil {
+		return err
+	}
+	store.Meta.FetchedAt = time.Now()
+	return WriteFile(path, store)
+}
+
+// ReadFile loads the catalog at path into target.
+func ReadFile(path string, target *Store) error {
+	file, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrCorruptStore, err)
+	}
+	if err := json.Unmarshal(file, target); err != nil {
+		return fmt.Errorf("%w: %s: %w", ErrCorruptStore, path, err)
+	}
+	return nil
+}
+
+// WriteFile atomically replaces the store at path, so a failed write never leaves a half written catalog behind.
+func WriteFile(path string, store Store) error {
+	jsonString, err := json.Marshal(store)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(jsonString); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
 }
 
 type Entry struct {
@@ -41,119 +105,33 @@ type Entry struct {
 type Category struct {
 	Name        string
 	Description string
+	Parent      string   // Name of the super-category, empty for top level categories
+	Children    []string // Names of the subcategories, in the order they are listed
 	Entries     []Entry
 }
 
-type Store struct {
-	Entries    []Entry
-	Categories []Category
+// Meta records where a store came from so it can be refreshed later.
+type Meta struct {
+	Source       string
+	ETag         string
+	LastModified string
+	Revision     string    // ETag when the source has one, otherwise a hash of the raw catalog
+	FetchedAt    time.Time // Last time the source was checked, bumped even when nothing changed
 }
 
-func FetchAndParseMD() Store {
-	store := Store{
-		Entries:    []Entry{},
-		Categories: []Category{},
-	}
-
-	resp, err := http.Get("https://raw.githubusercontent.com/avelino/awesome-go/main/README.md")
-	if err != nil {
-		fmt.Println("Error getting readme")
-		panic(err)
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		fmt.Println("Error reading readme body")
-		panic(err)
-	}
-
-	b := string(body)
-
-	// Step 1 - parse each section
-	sections := strings.Split(b, "**[⬆ back to top](#contents)**")
-
-	for _, section := range sections {
-		sectionLines := strings.Split(section, "\n")
-
-		c := Category{
-			Name:        "",
-			Description: "",
-			Entries:     []Entry{},
-		}
-
-		j := 0
-		for j < len(sectionLines) {
-			line := sectionLines[j]
-
-			// Case 1 - We find a line that contains a Category (## or ###)
-			if strings.Contains(line, "##") {
-				// If we find a SuperClass, we skip it if it has children classes
-				search := j + 1
-				for sectionLines[search] == "" {
-					search += 1
-				}
-
-				// If it has a children class we just want to skip this line.
-				if strings.Contains(sectionLines[search], "###") {
-					j += 1
-					continue
-				}
-
-				normalizedName := strings.Replace(line, "#", "", 3)
-				c.Name = normalizedName
-
-				// Otherwise we see if it has a Description
-				if strings.Contains(sectionLines[search], "._") {
-
-					normalizeDescription := strings.TrimLeft(sectionLines[search], "_")
-					normalizeDescription = strings.TrimRight(normalizeDescription, "._")
-					c.Description = normalizeDescription
-					j = search + 1
-					continue
-				}
-			}
-
-			// Case 2 - The line is a link or package essentially
-			// format example:
-			// - [bingo](https://github.com/iancmcc/bingo) - Fast, zero-allocation, lexicographical-order-preserving packing of native types to bytes.
-			if strings.Contains(line, "(http") {
-				e := Entry{
-					Category:    c.Name,
-					Name:        "",
-					Link:        "",
-					Description: "",
-				}
-
-				title := match(line, "[", "]")
-				link := match(line, "(", ")")
-				e.Name = title
-				e.Link = link
-
-				// Attempt to find a description
-				entrySections := strings.Split(line, ") -")
-				if len(entrySections) > 1 {
-					e.Description = entrySections[1]
-				}
-
-				c.Entries = append(c.Entries, e)
-				store.Entries = append(store.Entries, e)
-			}
-			j += 1
-		}
-		store.Categories = append(store.Categories, c)
-	}
+// Stale reports whether the catalog was last fetched more than ttl ago.
+// Stores written before FetchedAt was recorded are always stale.
+func (m Meta) Stale(ttl time.Duration, now time.Time) bool {
+	return m.FetchedAt.IsZero() || now.Sub(m.FetchedAt) > ttl
+}
 
-	return store
+type Store struct {
+	Meta       Meta
+	Entries    []Entry
+	Categories []Category
 }
 
-func match(s string, openingBracket string, closingBracket string) string {
-	i := strings.Index(s, openingBracket)
-	if i >= 0 {
-		j := strings.Index(s, closingBracket)
-		if j >= 0 {
-			return s[i+1 : j]
-		}
-	}
-	return ""
+// FetchAndParseMD downloads and parses the awesome-go README.
+func FetchAndParseMD() (Store, error) {
+	return AwesomeGo().Fetch()
 }
@@ -0,0 +1,54 @@
+package store
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestInitFromFixture(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache", FileName)
+	src := FileSource{Path: filepath.Join("testdata", "README.md")}
+
+	if err := Init(path, src); err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+	var got Store
+	if err := ReadFile(path, &got); err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if len(got.Entries) == 0 || len(got.Categories) == 0 {
+		t.Fatalf("got %d entries and %d categories, want the fixture's", len(got.Entries), len(got.Categories))
+	}
+	if got.Meta.Source != src.Location() || got.Meta.Revision == "" || got.Meta.FetchedAt.IsZero() {
+		t.Errorf("Meta = %+v, want the source, a revision and a fetch time", got.Meta)
+	}
+
+	// An existing catalog is left alone, even with another source
+	if err := Init(path, FileSource{Path: filepath.Join("testdata", "missing.md")}); err != nil {
+		t.Errorf("Init over an existing catalog: %v", err)
+	}
+}
+
+func TestInitMissingSource(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	err := Init(path, FileSource{Path: filepath.Join("testdata", "missing.md")})
+	if !errors.Is(err, ErrFetch) {
+		t.Errorf("got %v, want ErrFetch", err)
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("nothing should be written when the fetch fails")
+	}
+}
+
+func TestReadFileCorrupt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	if err := os.WriteFile(path, []byte("{not json"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var s Store
+	if err := ReadFile(path, &s); !errors.Is(err, ErrCorruptStore) {
+		t.Errorf("got %v, want ErrCorruptStore", err)
+	}
+}